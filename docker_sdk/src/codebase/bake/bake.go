@@ -0,0 +1,298 @@
+package bake
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// fileSchema describes the blocks supported in a bake file.
+//
+// Other blocks (e.g., function) are ignored.
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "group", LabelNames: []string{"name"}},
+		{Type: "target", LabelNames: []string{"name"}},
+	},
+}
+
+// functions are the functions that can be used in bake file expressions.
+var functions = map[string]function.Function{
+	"coalesce":      stdlib.CoalesceFunc,
+	"concat":        stdlib.ConcatFunc,
+	"contains":      stdlib.ContainsFunc,
+	"distinct":      stdlib.DistinctFunc,
+	"equal":         stdlib.EqualFunc,
+	"format":        stdlib.FormatFunc,
+	"join":          stdlib.JoinFunc,
+	"length":        stdlib.LengthFunc,
+	"lower":         stdlib.LowerFunc,
+	"merge":         stdlib.MergeFunc,
+	"notequal":      stdlib.NotEqualFunc,
+	"regex":         stdlib.RegexFunc,
+	"regex_replace": stdlib.RegexReplaceFunc,
+	"replace":       stdlib.ReplaceFunc,
+	"split":         stdlib.SplitFunc,
+	"substr":        stdlib.SubstrFunc,
+	"trimprefix":    stdlib.TrimPrefixFunc,
+	"trimspace":     stdlib.TrimSpaceFunc,
+	"trimsuffix":    stdlib.TrimSuffixFunc,
+	"upper":         stdlib.UpperFunc,
+}
+
+// Bake represents a parsed Buildx bake file (`docker-bake.hcl` or
+// `docker-bake.json`).
+type Bake struct {
+	// filename is the name of the bake file.
+	filename string
+
+	// variables are the variables declared in the bake file.
+	variables []*Variable
+
+	// groups are the groups of targets declared in the bake file.
+	groups []*Group
+
+	// targets are the targets declared in the bake file.
+	//
+	// Matrix targets are expanded into one target per combination.
+	targets []*Target
+}
+
+// NewBake parses a bake file from its content.
+//
+// The file is parsed as JSON if its extension is `.json`, as HCL otherwise.
+// Variables defaults are used to expand matrix targets and resolve groups.
+func NewBake(filename string, content []byte) (*Bake, error) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+
+	if filepath.Ext(filename) == ".json" {
+		file, diags = json.Parse(content, filename)
+	} else {
+		file, diags = hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	}
+
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, diags)
+	}
+
+	body, _, diags := file.Body.PartialContent(fileSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, diags)
+	}
+
+	b := &Bake{
+		filename: filename,
+	}
+
+	for _, block := range body.Blocks.OfType("variable") {
+		variable, err := newVariable(block)
+		if err != nil {
+			return nil, err
+		}
+
+		b.variables = append(b.variables, variable)
+	}
+
+	defaults, err := b.resolveVariables(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range body.Blocks.OfType("target") {
+		targets, err := newTargets(b, block, defaults)
+		if err != nil {
+			return nil, err
+		}
+
+		b.targets = append(b.targets, targets...)
+	}
+
+	for _, block := range body.Blocks.OfType("group") {
+		group, err := newGroup(block, defaults)
+		if err != nil {
+			return nil, err
+		}
+
+		b.groups = append(b.groups, group)
+	}
+
+	return b, nil
+}
+
+// Filename returns the name of the bake file.
+func (b *Bake) Filename() string {
+	return b.filename
+}
+
+// Variables returns the variables declared in the bake file.
+func (b *Bake) Variables() []*Variable {
+	return b.variables
+}
+
+// Targets returns the targets declared in the bake file.
+func (b *Bake) Targets() []*Target {
+	return b.targets
+}
+
+// Groups returns the groups declared in the bake file.
+func (b *Bake) Groups() []*Group {
+	return b.groups
+}
+
+// GetVariable retrieves a variable by its name.
+func (b *Bake) GetVariable(name string) (*Variable, error) {
+	for _, variable := range b.variables {
+		if variable.Name() == name {
+			return variable, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no such variable: %s", name)
+}
+
+// GetTarget retrieves a target by its name.
+func (b *Bake) GetTarget(name string) (*Target, error) {
+	for _, target := range b.targets {
+		if target.Name() == name {
+			return target, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no such target: %s", name)
+}
+
+// GetGroup retrieves a group by its name.
+func (b *Bake) GetGroup(name string) (*Group, error) {
+	for _, group := range b.groups {
+		if group.Name() == name {
+			return group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no such group: %s", name)
+}
+
+// Defaults returns the default value of every variable.
+func (b *Bake) Defaults() (map[string]cty.Value, error) {
+	return b.resolveVariables(nil)
+}
+
+// ResolveTargets returns the targets referenced by the given name.
+//
+// The name can be a group, whose targets are resolved recursively, a
+// target or a matrix target, which resolves to all its combinations.
+func (b *Bake) ResolveTargets(name string) ([]*Target, error) {
+	targets := []*Target{}
+	seen := map[string]bool{}
+
+	if err := b.resolveTargets(name, &targets, seen, []string{}); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// resolveTargets recursively adds the targets referenced by name.
+//
+// Path holds the groups being resolved to detect cycles.
+func (b *Bake) resolveTargets(name string, targets *[]*Target, seen map[string]bool, path []string) error {
+	for _, group := range path {
+		if group == name {
+			return fmt.Errorf("group %s includes itself", name)
+		}
+	}
+
+	if group, err := b.GetGroup(name); err == nil {
+		for _, member := range group.Targets() {
+			if err := b.resolveTargets(member, targets, seen, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	found := false
+	for _, target := range b.targets {
+		if target.Name() != name && target.BlockName() != name {
+			continue
+		}
+
+		found = true
+		if !seen[target.Name()] {
+			*targets = append(*targets, target)
+			seen[target.Name()] = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no such target or group: %s", name)
+	}
+
+	return nil
+}
+
+// resolveVariables evaluates the value of every variable.
+//
+// A variable takes its value from values if set, from its default
+// otherwise.
+// Defaults may reference other variables so they are evaluated until all of
+// them are resolved.
+func (b *Bake) resolveVariables(values map[string]cty.Value) (map[string]cty.Value, error) {
+	resolved := map[string]cty.Value{}
+	pending := []*Variable{}
+
+	for _, variable := range b.variables {
+		if value, exist := values[variable.Name()]; exist {
+			resolved[variable.Name()] = value
+
+			continue
+		}
+
+		pending = append(pending, variable)
+	}
+
+	for len(pending) != 0 {
+		remaining := []*Variable{}
+		var lastErr error
+
+		for _, variable := range pending {
+			value, err := variable.evalDefault(evalContext(resolved))
+			if err != nil {
+				remaining = append(remaining, variable)
+				lastErr = err
+
+				continue
+			}
+
+			resolved[variable.Name()] = value
+		}
+
+		// No variable could be resolved during this pass, so the remaining
+		// ones reference unknown or circular variables.
+		if len(remaining) == len(pending) {
+			return nil, fmt.Errorf("failed to resolve variables: %w", lastErr)
+		}
+
+		pending = remaining
+	}
+
+	return resolved, nil
+}
+
+// evalContext returns an evaluation context with the given variables and the
+// supported functions.
+func evalContext(variables map[string]cty.Value) *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: variables,
+		Functions: functions,
+	}
+}
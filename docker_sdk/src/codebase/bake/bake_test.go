@@ -0,0 +1,298 @@
+package bake
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  cty.Value
+		want    []map[string]string
+		wantErr string
+	}{
+		{
+			name: "object",
+			matrix: cty.ObjectVal(map[string]cty.Value{
+				"mode": cty.TupleVal([]cty.Value{cty.StringVal("dev"), cty.StringVal("prod")}),
+				"arch": cty.TupleVal([]cty.Value{cty.StringVal("amd64")}),
+			}),
+			want: []map[string]string{
+				{"arch": "amd64", "mode": "dev"},
+				{"arch": "amd64", "mode": "prod"},
+			},
+		},
+		{
+			name: "map",
+			matrix: cty.MapVal(map[string]cty.Value{
+				"mode": cty.ListVal([]cty.Value{cty.StringVal("dev"), cty.StringVal("prod")}),
+			}),
+			want: []map[string]string{
+				{"mode": "dev"},
+				{"mode": "prod"},
+			},
+		},
+		{
+			name:    "not an object",
+			matrix:  cty.StringVal("dev"),
+			wantErr: "matrix must be an object",
+		},
+		{
+			name: "value not a list",
+			matrix: cty.ObjectVal(map[string]cty.Value{
+				"mode": cty.StringVal("dev"),
+			}),
+			wantErr: "matrix value mode must be a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combinations, err := expandMatrix(tt.matrix)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandMatrix() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []map[string]string{}
+			for _, combination := range combinations {
+				values := map[string]string{}
+				for key, value := range combination {
+					values[key] = value.AsString()
+				}
+
+				got = append(got, values)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandMatrix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetConfig(t *testing.T) {
+	content := `
+variable "TAG" {
+  default = "latest"
+}
+
+variable "IMAGE" {
+  default = "app:${TAG}"
+}
+
+target "base" {
+  context    = "."
+  dockerfile = "base.Dockerfile"
+  args = {
+    BASE = "alpine"
+    MODE = "debug"
+  }
+}
+
+target "app" {
+  inherits = ["base"]
+  target   = "runtime"
+  tags     = ["${IMAGE}"]
+  args = {
+    MODE = "release"
+  }
+}
+
+target "matrix" {
+  name     = "app-${mode}"
+  inherits = ["base"]
+  matrix = {
+    mode = ["dev", "prod"]
+  }
+  args = {
+    MODE = mode
+  }
+}
+
+target "loop-a" {
+  inherits = ["loop-b"]
+}
+
+target "loop-b" {
+  inherits = ["loop-a"]
+}
+`
+
+	tests := []struct {
+		name    string
+		target  string
+		values  map[string]cty.Value
+		want    *Config
+		wantErr string
+	}{
+		{
+			name:   "inherits and expands variables",
+			target: "app",
+			want: &Config{
+				Context:    ".",
+				Dockerfile: "base.Dockerfile",
+				Target:     "runtime",
+				Args:       map[string]string{"BASE": "alpine", "MODE": "release"},
+				Tags:       []string{"app:latest"},
+			},
+		},
+		{
+			name:   "variable values override defaults",
+			target: "app",
+			values: map[string]cty.Value{"TAG": cty.StringVal("v1")},
+			want: &Config{
+				Context:    ".",
+				Dockerfile: "base.Dockerfile",
+				Target:     "runtime",
+				Args:       map[string]string{"BASE": "alpine", "MODE": "release"},
+				Tags:       []string{"app:v1"},
+			},
+		},
+		{
+			name:   "matrix combination",
+			target: "app-prod",
+			want: &Config{
+				Context:    ".",
+				Dockerfile: "base.Dockerfile",
+				Args:       map[string]string{"BASE": "alpine", "MODE": "prod"},
+			},
+		},
+		{
+			name:    "inheritance cycle",
+			target:  "loop-a",
+			wantErr: "inherits from itself",
+		},
+	}
+
+	bake, err := NewBake("docker-bake.hcl", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := bake.GetTarget(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := target.Config(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Config() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(config, tt.want) {
+				t.Errorf("Config() = %+v, want %+v", config, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTargets(t *testing.T) {
+	content := `
+target "api" {}
+
+target "web" {
+  name   = "web-${mode}"
+  matrix = {
+    mode = ["dev", "prod"]
+  }
+}
+
+group "default" {
+  targets = ["api", "frontend"]
+}
+
+group "frontend" {
+  targets = ["web", "api"]
+}
+
+group "loop" {
+  targets = ["loop"]
+}
+`
+
+	tests := []struct {
+		name    string
+		target  string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "target",
+			target: "api",
+			want:   []string{"api"},
+		},
+		{
+			name:   "matrix block",
+			target: "web",
+			want:   []string{"web-dev", "web-prod"},
+		},
+		{
+			name:   "nested groups without duplicates",
+			target: "default",
+			want:   []string{"api", "web-dev", "web-prod"},
+		},
+		{
+			name:    "group including itself",
+			target:  "loop",
+			wantErr: "group loop includes itself",
+		},
+		{
+			name:    "unknown",
+			target:  "missing",
+			wantErr: "no such target or group: missing",
+		},
+	}
+
+	bake, err := NewBake("docker-bake.hcl", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := bake.ResolveTargets(tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveTargets() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, target := range targets {
+				names = append(names, target.Name())
+			}
+
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("ResolveTargets() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
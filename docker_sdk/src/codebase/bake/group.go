@@ -0,0 +1,56 @@
+package bake
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// groupSchema describes the attributes of a group block.
+var groupSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "targets", Required: true},
+		{Name: "description"},
+	},
+}
+
+// Group represents a group of targets declared in a bake file.
+type Group struct {
+	// name is the name of the group.
+	name string
+
+	// targets are the names of the targets or groups in the group.
+	targets []string
+}
+
+// newGroup parses a group block, its targets are evaluated with the given
+// variables.
+func newGroup(block *hcl.Block, variables map[string]cty.Value) (*Group, error) {
+	name := block.Labels[0]
+
+	content, diags := block.Body.Content(groupSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid group %s: %w", name, diags)
+	}
+
+	targets, err := evalStringList(content.Attributes["targets"], evalContext(variables))
+	if err != nil {
+		return nil, fmt.Errorf("invalid group %s: %w", name, err)
+	}
+
+	return &Group{
+		name:    name,
+		targets: targets,
+	}, nil
+}
+
+// Name returns the name of the group.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Targets returns the names of the targets or groups in the group.
+func (g *Group) Targets() []string {
+	return g.targets
+}
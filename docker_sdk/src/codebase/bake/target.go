@@ -0,0 +1,448 @@
+package bake
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Config holds the build configuration of a target, after evaluation of its
+// expressions and inheritance.
+type Config struct {
+	// Context is the build context path.
+	Context string
+
+	// Dockerfile is the path to the Dockerfile relative to the context.
+	Dockerfile string
+
+	// Target is the stage to build, empty for the last stage.
+	Target string
+
+	// Args are the build arguments.
+	Args map[string]string
+
+	// Platforms are the platforms to build, empty for the default platform.
+	Platforms []string
+
+	// Tags are the image references of the target.
+	Tags []string
+}
+
+// merge overrides the configuration with the values set in other.
+//
+// Build arguments are merged key by key.
+func (c *Config) merge(other *Config) {
+	if other.Context != "" {
+		c.Context = other.Context
+	}
+
+	if other.Dockerfile != "" {
+		c.Dockerfile = other.Dockerfile
+	}
+
+	if other.Target != "" {
+		c.Target = other.Target
+	}
+
+	for key, value := range other.Args {
+		c.Args[key] = value
+	}
+
+	if other.Platforms != nil {
+		c.Platforms = other.Platforms
+	}
+
+	if other.Tags != nil {
+		c.Tags = other.Tags
+	}
+}
+
+// Target represents a target declared in a bake file.
+type Target struct {
+	// bake is the bake file declaring the target.
+	bake *Bake
+
+	// name is the name of the target.
+	name string
+
+	// blockName is the label of the target block.
+	//
+	// It's the same as name unless the target is a matrix combination.
+	blockName string
+
+	// attributes are the attributes declared in the target block.
+	attributes hcl.Attributes
+
+	// matrix holds the values of the matrix variables for this combination.
+	matrix map[string]cty.Value
+}
+
+// newTargets parses a target block.
+//
+// A matrix target is expanded into one target per combination of its matrix
+// values, each named after the evaluation of its `name` attribute.
+func newTargets(b *Bake, block *hcl.Block, variables map[string]cty.Value) ([]*Target, error) {
+	blockName := block.Labels[0]
+
+	attributes, diags := block.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid target %s: %w", blockName, diags)
+	}
+
+	matrixAttr, exist := attributes["matrix"]
+	if !exist {
+		return []*Target{{
+			bake:       b,
+			name:       blockName,
+			blockName:  blockName,
+			attributes: attributes,
+		}}, nil
+	}
+
+	nameAttr, exist := attributes["name"]
+	if !exist {
+		return nil, fmt.Errorf("invalid target %s: a matrix target must set a name", blockName)
+	}
+
+	matrix, diags := matrixAttr.Expr.Value(evalContext(variables))
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid matrix of target %s: %w", blockName, diags)
+	}
+
+	combinations, err := expandMatrix(matrix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid matrix of target %s: %w", blockName, err)
+	}
+
+	targets := []*Target{}
+	for _, combination := range combinations {
+		name, err := evalString(nameAttr, evalContext(mergeValues(variables, combination)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid name of target %s: %w", blockName, err)
+		}
+
+		targets = append(targets, &Target{
+			bake:       b,
+			name:       name,
+			blockName:  blockName,
+			attributes: attributes,
+			matrix:     combination,
+		})
+	}
+
+	return targets, nil
+}
+
+// Name returns the name of the target.
+func (t *Target) Name() string {
+	return t.name
+}
+
+// BlockName returns the label of the target block.
+func (t *Target) BlockName() string {
+	return t.blockName
+}
+
+// Variables returns the bake variables the target depends on.
+//
+// It includes the variables referenced by the target, its parents and by the
+// defaults of these variables.
+func (t *Target) Variables() []*Variable {
+	names := map[string]bool{}
+	t.collectVariables(names, map[string]bool{})
+
+	// Add the variables referenced by the defaults until there's no more.
+	for added := true; added; {
+		added = false
+
+		for _, variable := range t.bake.variables {
+			if !names[variable.Name()] {
+				continue
+			}
+
+			for _, reference := range variable.references() {
+				if !names[reference] {
+					names[reference] = true
+					added = true
+				}
+			}
+		}
+	}
+
+	variables := []*Variable{}
+	for _, variable := range t.bake.variables {
+		if names[variable.Name()] {
+			variables = append(variables, variable)
+		}
+	}
+
+	return variables
+}
+
+// collectVariables adds the names referenced by the target and its parents.
+func (t *Target) collectVariables(names map[string]bool, visited map[string]bool) {
+	if visited[t.name] {
+		return
+	}
+	visited[t.name] = true
+
+	for key, attr := range t.attributes {
+		// The matrix and name attributes are already evaluated.
+		if key == "matrix" || key == "name" {
+			continue
+		}
+
+		for _, name := range rootNames(attr.Expr) {
+			names[name] = true
+		}
+	}
+
+	for _, parent := range t.parents() {
+		parent.collectVariables(names, visited)
+	}
+}
+
+// parents returns the targets this target inherits from, evaluated with the
+// variables defaults.
+//
+// Unknown parents are ignored, they are reported by Config.
+func (t *Target) parents() []*Target {
+	defaults, err := t.bake.resolveVariables(nil)
+	if err != nil {
+		return nil
+	}
+
+	names, err := t.inherits(defaults)
+	if err != nil {
+		return nil
+	}
+
+	parents := []*Target{}
+	for _, name := range names {
+		parent, err := t.bake.GetTarget(name)
+		if err != nil {
+			continue
+		}
+
+		parents = append(parents, parent)
+	}
+
+	return parents
+}
+
+// inherits evaluates the names of the targets this target inherits from.
+func (t *Target) inherits(variables map[string]cty.Value) ([]string, error) {
+	attr, exist := t.attributes["inherits"]
+	if !exist {
+		return nil, nil
+	}
+
+	return evalStringList(attr, evalContext(mergeValues(variables, t.matrix)))
+}
+
+// Config evaluates the build configuration of the target.
+//
+// Values override the default of the variables with the same name.
+func (t *Target) Config(values map[string]cty.Value) (*Config, error) {
+	variables, err := t.bake.resolveVariables(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.config(variables, []string{})
+}
+
+// config evaluates the build configuration of the target and its parents.
+//
+// Path holds the targets being evaluated to detect inheritance cycles.
+func (t *Target) config(variables map[string]cty.Value, path []string) (*Config, error) {
+	for _, name := range path {
+		if name == t.name {
+			return nil, fmt.Errorf("target %s inherits from itself", t.name)
+		}
+	}
+	path = append(path, t.name)
+
+	config := &Config{
+		Args: map[string]string{},
+	}
+
+	parents, err := t.inherits(variables)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inherits of target %s: %w", t.name, err)
+	}
+
+	for _, name := range parents {
+		parent, err := t.bake.GetTarget(name)
+		if err != nil {
+			return nil, fmt.Errorf("target %s inherits from unknown target: %w", t.name, err)
+		}
+
+		parentConfig, err := parent.config(variables, path)
+		if err != nil {
+			return nil, err
+		}
+
+		config.merge(parentConfig)
+	}
+
+	own, err := t.ownConfig(evalContext(mergeValues(variables, t.matrix)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %s: %w", t.name, err)
+	}
+
+	config.merge(own)
+
+	return config, nil
+}
+
+// ownConfig evaluates the attributes declared in the target block.
+//
+// Unsupported attributes (e.g., cache-from, output) are ignored.
+func (t *Target) ownConfig(ctx *hcl.EvalContext) (*Config, error) {
+	config := &Config{
+		Args: map[string]string{},
+	}
+
+	var err error
+	for key, attr := range t.attributes {
+		switch key {
+		case "context":
+			config.Context, err = evalString(attr, ctx)
+		case "dockerfile":
+			config.Dockerfile, err = evalString(attr, ctx)
+		case "target":
+			config.Target, err = evalString(attr, ctx)
+		case "platforms":
+			config.Platforms, err = evalStringList(attr, ctx)
+		case "tags":
+			config.Tags, err = evalStringList(attr, ctx)
+		case "args":
+			config.Args, err = evalStringMap(attr, ctx)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// expandMatrix returns every combination of the matrix values.
+//
+// The matrix is an object or a map whose values are lists of values.
+func expandMatrix(matrix cty.Value) ([]map[string]cty.Value, error) {
+	if !matrix.Type().IsObjectType() && !matrix.Type().IsMapType() {
+		return nil, fmt.Errorf("matrix must be an object")
+	}
+
+	// Values are read from the value map since GetAttr panics on maps.
+	valueMap := matrix.AsValueMap()
+
+	keys := []string{}
+	for key := range valueMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]cty.Value{{}}
+	for _, key := range keys {
+		values := valueMap[key]
+		if !values.CanIterateElements() {
+			return nil, fmt.Errorf("matrix value %s must be a list", key)
+		}
+
+		expanded := []map[string]cty.Value{}
+		for _, combination := range combinations {
+			for _, value := range values.AsValueSlice() {
+				next := mergeValues(combination, map[string]cty.Value{key: value})
+				expanded = append(expanded, next)
+			}
+		}
+
+		combinations = expanded
+	}
+
+	return combinations, nil
+}
+
+// mergeValues returns a new map with the values of both maps, values of b
+// take precedence.
+func mergeValues(a, b map[string]cty.Value) map[string]cty.Value {
+	merged := map[string]cty.Value{}
+
+	for key, value := range a {
+		merged[key] = value
+	}
+
+	for key, value := range b {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// evalString evaluates an attribute as a string.
+func evalString(attr *hcl.Attribute, ctx *hcl.EvalContext) (string, error) {
+	value, err := evalAs(attr, ctx, cty.String)
+	if err != nil || value.IsNull() {
+		return "", err
+	}
+
+	return value.AsString(), nil
+}
+
+// evalStringList evaluates an attribute as a list of strings.
+func evalStringList(attr *hcl.Attribute, ctx *hcl.EvalContext) ([]string, error) {
+	value, err := evalAs(attr, ctx, cty.List(cty.String))
+	if err != nil || value.IsNull() {
+		return nil, err
+	}
+
+	list := []string{}
+	for _, element := range value.AsValueSlice() {
+		if !element.IsNull() {
+			list = append(list, element.AsString())
+		}
+	}
+
+	return list, nil
+}
+
+// evalStringMap evaluates an attribute as a map of strings.
+//
+// Null values are skipped.
+func evalStringMap(attr *hcl.Attribute, ctx *hcl.EvalContext) (map[string]string, error) {
+	value, err := evalAs(attr, ctx, cty.Map(cty.String))
+	if err != nil || value.IsNull() {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for key, element := range value.AsValueMap() {
+		if !element.IsNull() {
+			result[key] = element.AsString()
+		}
+	}
+
+	return result, nil
+}
+
+// evalAs evaluates an attribute and converts it to the given type.
+func evalAs(attr *hcl.Attribute, ctx *hcl.EvalContext, ty cty.Type) (cty.Value, error) {
+	value, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("invalid %s: %w", attr.Name, diags)
+	}
+
+	converted, err := convert.Convert(value, ty)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s: %w", attr.Name, err)
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,190 @@
+package bake
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// variableSchema describes the attributes of a variable block.
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+// VariableType is the type of a bake variable, inferred from its default
+// value.
+type VariableType string
+
+const (
+	// VariableTypeString indicates a string variable.
+	VariableTypeString VariableType = "string"
+	// VariableTypeBool indicates a boolean variable.
+	VariableTypeBool VariableType = "bool"
+	// VariableTypeNumber indicates an integer variable.
+	VariableTypeNumber VariableType = "number"
+)
+
+// Variable represents a variable declared in a bake file.
+type Variable struct {
+	// name is the name of the variable.
+	name string
+
+	// description is the description of the variable, if any.
+	description string
+
+	// defaultExpr is the expression of the variable's default value, nil if
+	// the variable has no default.
+	defaultExpr hcl.Expression
+}
+
+// newVariable parses a variable block.
+func newVariable(block *hcl.Block) (*Variable, error) {
+	name := block.Labels[0]
+
+	content, diags := block.Body.Content(variableSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid variable %s: %w", name, diags)
+	}
+
+	variable := &Variable{
+		name: name,
+	}
+
+	if attr, exist := content.Attributes["default"]; exist {
+		variable.defaultExpr = attr.Expr
+	}
+
+	if attr, exist := content.Attributes["description"]; exist {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || value.Type() != cty.String {
+			return nil, fmt.Errorf("invalid variable %s: description must be a string", name)
+		}
+
+		variable.description = value.AsString()
+	}
+
+	return variable, nil
+}
+
+// Name returns the name of the variable.
+func (v *Variable) Name() string {
+	return v.name
+}
+
+// Description returns the description of the variable.
+func (v *Variable) Description() string {
+	return v.description
+}
+
+// Type returns the type of the variable.
+//
+// It's inferred from the default value, variables without default or with a
+// default that depends on other variables are strings.
+func (v *Variable) Type() VariableType {
+	value, err := v.evalDefault(nil)
+	if err != nil {
+		return VariableTypeString
+	}
+
+	switch value.Type() {
+	case cty.Bool:
+		return VariableTypeBool
+	case cty.Number:
+		if value.AsBigFloat().IsInt() {
+			return VariableTypeNumber
+		}
+	}
+
+	return VariableTypeString
+}
+
+// Default returns the default value of the variable evaluated against the
+// defaults of the other variables, and true if the variable has one.
+//
+// The value is a string, a bool or an int64 depending on the variable's type.
+func (v *Variable) Default(variables map[string]cty.Value) (any, bool) {
+	if v.defaultExpr == nil {
+		return nil, false
+	}
+
+	value, err := v.evalDefault(evalContext(variables))
+	if err != nil || value.IsNull() {
+		return nil, false
+	}
+
+	switch v.Type() {
+	case VariableTypeBool:
+		return value.True(), true
+	case VariableTypeNumber:
+		number, _ := value.AsBigFloat().Int64()
+
+		return number, true
+	}
+
+	if value.Type() != cty.String {
+		return nil, false
+	}
+
+	return value.AsString(), true
+}
+
+// Value converts a value set by the user to a value usable in the bake file
+// expressions.
+//
+// Supported values are strings, bools and int64.
+func (v *Variable) Value(value any) cty.Value {
+	switch value := value.(type) {
+	case bool:
+		return cty.BoolVal(value)
+	case int64:
+		return cty.NumberVal(new(big.Float).SetInt64(value))
+	case int:
+		return cty.NumberIntVal(int64(value))
+	case string:
+		return cty.StringVal(value)
+	}
+
+	return cty.StringVal(fmt.Sprint(value))
+}
+
+// evalDefault evaluates the default value of the variable.
+//
+// A variable without default is an empty string.
+func (v *Variable) evalDefault(ctx *hcl.EvalContext) (cty.Value, error) {
+	if v.defaultExpr == nil {
+		return cty.StringVal(""), nil
+	}
+
+	value, diags := v.defaultExpr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("invalid default of variable %s: %w", v.name, diags)
+	}
+
+	return value, nil
+}
+
+// references returns the names of the variables referenced by the default
+// value of the variable.
+func (v *Variable) references() []string {
+	if v.defaultExpr == nil {
+		return nil
+	}
+
+	return rootNames(v.defaultExpr)
+}
+
+// rootNames returns the root names of the variables referenced by the
+// expression.
+func rootNames(expr hcl.Expression) []string {
+	names := []string{}
+	for _, traversal := range expr.Variables() {
+		names = append(names, traversal.RootName())
+	}
+
+	return names
+}
@@ -5,36 +5,61 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"dagger.io/dockersdk/codebase/bake"
 	"dagger.io/dockersdk/codebase/dockercompose"
 	"dagger.io/dockersdk/codebase/dockerfile"
 	"dagger.io/dockersdk/codebase/finder"
+	"github.com/compose-spec/compose-go/types"
+	"github.com/moby/patternmatcher/ignorefile"
 )
 
 // CodebasePath is the mounted path of the codebase in the DockerSDK runtime
 // container.
 const CodebasePath = "/app"
 
+// IgnoreFilename is the name of the file listing paths to skip when looking
+// for Docker-related files in the codebase.
+//
+// It follows the `.dockerignore` syntax.
+const IgnoreFilename = ".dockersdkignore"
+
+// defaultExcludes are paths that never contain user's Docker-related files
+// and are always skipped.
+var defaultExcludes = []string{".git", "**/node_modules", "**/vendor"}
+
+// dockerfileFunctionPrefixes are the prefixes of the functions generated for
+// each Dockerfile, followed by its name (e.g., "BuildAll" and "BuildAllApi").
+var dockerfileFunctionPrefixes = []string{"Build", "BuildAll", "Publish", "Export"}
+
 // Codebase represents a codebase with Docker-related configurations.
 type Codebase struct {
-	// dockerfile points to the Dockerfile in the codebase.
-	dockerfile *dockerfile.Dockerfile
+	// dockerfiles points to the Dockerfiles in the codebase and its sub
+	// directories.
+	dockerfiles []*dockerfile.Dockerfile
 
 	// dockercompose points to the docker-compose file, if present.
 	dockercompose *dockercompose.DockerCompose
+
+	// bake points to the bake file, if present.
+	bake *bake.Bake
 }
 
 // New creates a new instance of Codebase by searching for Docker-related
 // files in user's host current directory.
 func New(ctx context.Context) (*Codebase, error) {
-	dir, err := os.ReadDir(CodebasePath)
+	excludes, err := getExcludes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read source directory: %w", err)
+		return nil, fmt.Errorf("failed to get %s: %w", IgnoreFilename, err)
 	}
 
-	finder := finder.New(CodebasePath, dir)
+	finder, err := finder.New(CodebasePath, excludes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source directory: %w", err)
+	}
 
-	dockerfile, dockerfileExists, err := getDockerfile(finder)
+	dockerfiles, err := getDockerfiles(finder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Dockerfile: %w", err)
 	}
@@ -44,45 +69,110 @@ func New(ctx context.Context) (*Codebase, error) {
 		return nil, fmt.Errorf("failed to get docker-compose file: %w", err)
 	}
 
-	if !dockerfileExists && !composeExistsExists {
-		return nil, fmt.Errorf("Dockerfile or docker-compose.yml not found in user project")
+	bakeFile, err := getBake(finder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bake file: %w", err)
 	}
 
-	// TODO: Check for sub directories/dockerfile
+	if len(dockerfiles) == 0 && !composeExistsExists && bakeFile == nil {
+		return nil, fmt.Errorf("Dockerfile, docker-compose.yml or docker-bake.hcl not found in user project")
+	}
 
 	return &Codebase{
-		dockerfile:    dockerfile,
+		dockerfiles:   dockerfiles,
 		dockercompose: dockercompose,
+		bake:          bakeFile,
 	}, nil
 }
 
-// getDockerfile searches for and returns a Dockerfile from the codebase,
-// along with its existence status.
-func getDockerfile(finder *finder.Finder) (*dockerfile.Dockerfile, bool, error) {
+// getExcludes returns the patterns of paths to skip when searching the
+// codebase.
+//
+// It's the default excludes plus the patterns listed in the codebase's
+// ignore file, if present.
+func getExcludes() ([]string, error) {
+	excludes := append([]string{}, defaultExcludes...)
+
+	file, err := os.Open(filepath.Join(CodebasePath, IgnoreFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return excludes, nil
+		}
+
+		return nil, fmt.Errorf("failed to open %s: %w", IgnoreFilename, err)
+	}
+	defer file.Close()
+
+	patterns, err := ignorefile.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", IgnoreFilename, err)
+	}
+
+	return append(excludes, patterns...), nil
+}
+
+// getDockerfiles searches for and returns the Dockerfiles from the codebase
+// and its sub directories.
+//
+// Returns an error if two Dockerfiles generate a function with the same name,
+// either because they have the same name or because a prefix and a name add
+// up to another function (e.g., `all.Dockerfile` builds with "BuildAll").
+func getDockerfiles(finder *finder.Finder) ([]*dockerfile.Dockerfile, error) {
 	patterns := []string{"Dockerfile", "*.Dockerfile"}
 
-	dockerfilePath, exist := finder.FindFileFromPattern(patterns)
-	if !exist {
-		return nil, false, nil
+	dockerfiles := []*dockerfile.Dockerfile{}
+	names := map[string]string{}
+
+	for _, dockerfilePath := range finder.FindFilesFromPattern(patterns) {
+		dockerfile, err := getDockerfile(finder, dockerfilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Functions are compared case insensitively since Dagger converts
+		// their names to camel case.
+		for _, prefix := range dockerfileFunctionPrefixes {
+			function := prefix + dockerfile.Name()
+
+			if path, exist := names[strings.ToLower(function)]; exist {
+				return nil, fmt.Errorf("%s and %s both generate the %s function, rename one of them", path, dockerfile.Path(), function)
+			}
+
+			names[strings.ToLower(function)] = dockerfile.Path()
+		}
+
+		dockerfiles = append(dockerfiles, dockerfile)
+	}
+
+	return dockerfiles, nil
+}
+
+// getDockerfile opens and parses the Dockerfile at the given path.
+func getDockerfile(finder *finder.Finder, dockerfilePath string) (*dockerfile.Dockerfile, error) {
+	relPath, err := finder.RelativePath(dockerfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative path of %s: %w", dockerfilePath, err)
 	}
 
 	file, err := os.Open(dockerfilePath)
 	if err != nil {
-		return nil, true, fmt.Errorf("failed to open Dockerfile: %w", err)
+		return nil, fmt.Errorf("failed to open %s: %w", relPath, err)
 	}
 	defer file.Close()
 
-	filename := filepath.Base(dockerfilePath)
-	dockerfile, err := dockerfile.NewDockerfile(filename, file)
+	dockerfile, err := dockerfile.NewDockerfile(relPath, file)
 	if err != nil {
-		return nil, true, fmt.Errorf("failed to parse Dockerfile: %w", err)
+		return nil, fmt.Errorf("failed to parse %s: %w", relPath, err)
 	}
 
-	return dockerfile, true, nil
+	return dockerfile, nil
 }
 
 // getDockerCompose searches for and returns a docker-compose configuration
 // from the codebase, along with its existence status.
+//
+// The override file of the docker-compose file (e.g.,
+// `docker-compose.override.yml`) is merged on top of it if present.
 func getDockerCompose(ctx context.Context, finder *finder.Finder) (*dockercompose.DockerCompose, bool, error) {
 	patterns := []string{"docker-compose.yml", "docker-compose.yaml", "compose.yaml", "compose.yml"}
 
@@ -91,16 +181,58 @@ func getDockerCompose(ctx context.Context, finder *finder.Finder) (*dockercompos
 		return nil, false, nil
 	}
 
-	filename := filepath.Base(dockerComposePath)
-	fileContent, err := os.ReadFile(dockerComposePath)
-	if err != nil {
-		return nil, true, fmt.Errorf("failed to get %s content: %w", filename, err)
+	paths := []string{dockerComposePath}
+
+	// Look for the override file with any of the YAML extensions, like
+	// docker compose does.
+	base := strings.TrimSuffix(filepath.Base(dockerComposePath), filepath.Ext(dockerComposePath))
+	overridePath, exist := finder.FindFileFromPattern([]string{base + ".override.yml", base + ".override.yaml"})
+	if exist {
+		paths = append(paths, overridePath)
+	}
+
+	files := []types.ConfigFile{}
+	for _, path := range paths {
+		filename := filepath.Base(path)
+		fileContent, err := os.ReadFile(path)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to get %s content: %w", filename, err)
+		}
+
+		files = append(files, types.ConfigFile{
+			Filename: filename,
+			Content:  fileContent,
+		})
 	}
 
-	compose, err := dockercompose.NewDockerCompose(ctx, filename, fileContent, finder)
+	compose, err := dockercompose.NewDockerCompose(ctx, filepath.Dir(dockerComposePath), files, finder)
 	if err != nil {
 		return nil, true, fmt.Errorf("failed to parse docker-compose.yml: %w", err)
 	}
 
 	return compose, true, nil
 }
+
+// getBake searches for and returns a bake file from the codebase, nil if
+// there's none.
+func getBake(finder *finder.Finder) (*bake.Bake, error) {
+	patterns := []string{"docker-bake.hcl", "docker-bake.json"}
+
+	bakePath, exist := finder.FindFileFromPattern(patterns)
+	if !exist {
+		return nil, nil
+	}
+
+	filename := filepath.Base(bakePath)
+	fileContent, err := os.ReadFile(bakePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s content: %w", filename, err)
+	}
+
+	bakeFile, err := bake.NewBake(filename, fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return bakeFile, nil
+}
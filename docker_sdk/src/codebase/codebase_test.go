@@ -0,0 +1,89 @@
+package codebase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dagger.io/dockersdk/codebase/finder"
+)
+
+func TestGetDockerfiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name:      "root and sub directories",
+			files:     []string{"Dockerfile", "api/Dockerfile", "worker.Dockerfile"},
+			wantNames: []string{"", "Api", "Worker"},
+		},
+		{
+			name:    "same name",
+			files:   []string{"api/Dockerfile", "api.Dockerfile"},
+			wantErr: "both generate the BuildApi function",
+		},
+		{
+			name:    "name clashing with the root BuildAll function",
+			files:   []string{"Dockerfile", "all.Dockerfile"},
+			wantErr: "both generate the BuildAll function",
+		},
+		{
+			name:    "prefix and name adding up to another function",
+			files:   []string{"foo.Dockerfile", "all-foo.Dockerfile"},
+			wantErr: "both generate the BuildAllFoo function",
+		},
+		{
+			name:      "all without root Dockerfile",
+			files:     []string{"all.Dockerfile"},
+			wantNames: []string{"All"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for _, file := range tt.files {
+				path := filepath.Join(dir, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(path, []byte("FROM alpine\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			finder, err := finder.New(dir, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dockerfiles, err := getDockerfiles(finder)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getDockerfiles() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, dockerfile := range dockerfiles {
+				names = append(names, dockerfile.Name())
+			}
+
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("getDockerfiles() names = %q, want %q", names, tt.wantNames)
+			}
+		})
+	}
+}
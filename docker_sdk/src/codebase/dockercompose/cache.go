@@ -6,6 +6,9 @@ type Cache struct {
 	name string
 	// path is the location where the cache is mounted inside the container.
 	path string
+	// named is true if the cache is a named volume, false if it replaces a
+	// bind mount whose host path doesn't exist.
+	named bool
 }
 
 // Name returns cache volume's name.
@@ -17,3 +20,8 @@ func (c *Cache) Name() string {
 func (c *Cache) Path() string {
 	return c.path
 }
+
+// Named returns true if the cache is a named volume of the compose file.
+func (c *Cache) Named() bool {
+	return c.named
+}
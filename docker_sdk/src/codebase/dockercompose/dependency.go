@@ -0,0 +1,38 @@
+package dockercompose
+
+import "github.com/compose-spec/compose-go/types"
+
+// DependencyCondition is the condition a dependency must meet before the
+// dependent service starts.
+type DependencyCondition string
+
+const (
+	// DependencyConditionStarted waits for the dependency to be started.
+	DependencyConditionStarted DependencyCondition = types.ServiceConditionStarted
+	// DependencyConditionHealthy waits for the dependency's healthcheck to
+	// succeed.
+	DependencyConditionHealthy DependencyCondition = types.ServiceConditionHealthy
+	// DependencyConditionCompletedSuccessfully waits for the dependency to
+	// run to completion with a zero exit code.
+	DependencyConditionCompletedSuccessfully DependencyCondition = types.ServiceConditionCompletedSuccessfully
+)
+
+// Dependency represents a service another service depends on.
+type Dependency struct {
+	// name is the name of the service depended on.
+	name string
+	// condition is the condition to meet before starting the dependent
+	// service.
+	condition DependencyCondition
+}
+
+// Name returns the name of the service depended on.
+func (d *Dependency) Name() string {
+	return d.name
+}
+
+// Condition returns the condition to meet before starting the dependent
+// service.
+func (d *Dependency) Condition() DependencyCondition {
+	return d.condition
+}
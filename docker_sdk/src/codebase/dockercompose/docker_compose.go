@@ -3,8 +3,11 @@ package dockercompose
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"dagger.io/dockersdk/codebase/finder"
+	"dagger.io/dockersdk/utils"
+	"github.com/compose-spec/compose-go/dotenv"
 	"github.com/compose-spec/compose-go/loader"
 	"github.com/compose-spec/compose-go/types"
 )
@@ -14,6 +17,17 @@ type DockerCompose struct {
 	// filename is the path to the Docker Compose file.
 	filename string
 
+	// files are the Docker Compose files merged into the project, in order.
+	files []types.ConfigFile
+
+	// workingDir is the directory of the Docker Compose file, relative paths
+	// of the project are resolved from it.
+	workingDir string
+
+	// environment holds the variables of the project's `.env` file, used to
+	// interpolate the Docker Compose files.
+	environment map[string]string
+
 	// project holds the parsed Docker Compose project configuration.
 	project *types.Project
 
@@ -21,41 +35,152 @@ type DockerCompose struct {
 	finder *finder.Finder
 }
 
-func NewDockerCompose(ctx context.Context, filename string, content []byte, finder *finder.Finder) (*DockerCompose, error) {
-	project, err := loader.LoadWithContext(ctx, types.ConfigDetails{
-		ConfigFiles: []types.ConfigFile{
-			{
-				Config: map[string]interface{}{
-					"name": "dockersdk",
-				},
-			},
-			{
-				Content: content,
+// NewDockerCompose loads a Docker Compose project from its files.
+//
+// Files are merged in order, so later files override the previous ones like
+// `docker compose -f a.yml -f b.yml`.
+// The first file is the main Docker Compose file.
+//
+// Variables of the `.env` file in the working directory are used to
+// interpolate the files, `env_file` entries are resolved from the working
+// directory too.
+func NewDockerCompose(ctx context.Context, workingDir string, files []types.ConfigFile, finder *finder.Finder) (*DockerCompose, error) {
+	environment, err := dotenv.GetEnvFromFile(map[string]string{}, workingDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	return newDockerCompose(ctx, workingDir, environment, files, finder)
+}
+
+// newDockerCompose loads a Docker Compose project from its files and the
+// given environment.
+func newDockerCompose(ctx context.Context, workingDir string, environment map[string]string, files []types.ConfigFile, finder *finder.Finder) (*DockerCompose, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no docker compose file to load")
+	}
+
+	filename := files[0].Filename
+
+	configFiles := []types.ConfigFile{
+		{
+			Config: map[string]interface{}{
+				"name": "dockersdk",
 			},
 		},
+	}
+	configFiles = append(configFiles, files...)
+
+	project, err := loader.LoadWithContext(ctx, types.ConfigDetails{
+		WorkingDir:  workingDir,
+		ConfigFiles: configFiles,
+		Environment: environment,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
 	}
 
-	return &DockerCompose{
-		filename: filename,
-		project:  project,
+	// The loader only resolves the environment of services enabled by the
+	// active profiles, so we resolve the others too.
+	disabled := types.Project{
+		Services:    project.DisabledServices,
+		Environment: project.Environment,
+	}
+	if err := disabled.ResolveServicesEnvironment(false); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+
+	compose := &DockerCompose{
+		filename:    filename,
+		files:       files,
+		workingDir:  workingDir,
+		environment: environment,
+		project:     project,
 		finder: finder,
-	}, nil
+	}
+
+	if err := compose.checkCycles(); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+
+	return compose, nil
+}
+
+// Filenames returns the names of the Docker Compose files merged into the
+// project.
+func (d *DockerCompose) Filenames() []string {
+	filenames := []string{}
+	for _, file := range d.files {
+		filenames = append(filenames, file.Filename)
+	}
+
+	return filenames
 }
 
-// Services returns all services defined in the Docker Compose file.
+// WithFiles returns a new DockerCompose merging the given files on top of the
+// current ones.
+func (d *DockerCompose) WithFiles(ctx context.Context, files []types.ConfigFile) (*DockerCompose, error) {
+	return newDockerCompose(ctx, d.workingDir, d.environment, append(append([]types.ConfigFile{}, d.files...), files...), d.finder)
+}
+
+// Services returns all services defined in the Docker Compose file,
+// whatever their profiles.
 func (d *DockerCompose) Services() []*Service {
-	services := make([]*Service, len(d.project.Services))
+	allServices := d.project.AllServices()
+	services := make([]*Service, len(allServices))
 
-	for i, service := range d.project.Services {
+	for i, service := range allServices {
 		services[i] = NewService(d, &service, d.finder)
 	}
 
 	return services
 }
 
+// ActiveServices returns the services enabled by the given profiles.
+//
+// Services without profiles are always enabled, the "*" profile enables all
+// services.
+func (d *DockerCompose) ActiveServices(profiles []string) []*Service {
+	services := []*Service{}
+
+	for _, service := range d.Services() {
+		if slices.Contains(profiles, "*") || service.s.HasProfile(profiles) {
+			services = append(services, service)
+		}
+	}
+
+	return services
+}
+
+// IsOneShot returns true if a service depends on the given service with the
+// `service_completed_successfully` condition, so it runs to completion
+// instead of running as a service.
+func (d *DockerCompose) IsOneShot(name string) bool {
+	for _, service := range d.Services() {
+		for _, dependency := range service.Dependencies() {
+			if dependency.Name() == name && dependency.Condition() == DependencyConditionCompletedSuccessfully {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Profiles returns the profiles declared by the services, sorted by name.
+func (d *DockerCompose) Profiles() []string {
+	profiles := []string{}
+
+	for _, service := range d.Services() {
+		profiles = append(profiles, service.Profiles()...)
+	}
+
+	profiles = utils.RemoveListDuplicates(profiles)
+	slices.Sort(profiles)
+
+	return profiles
+}
+
 // GetService retrieves a service by its name.
 func (d *DockerCompose) GetService(name string) (*Service, error) {
 	for _, service := range d.Services() {
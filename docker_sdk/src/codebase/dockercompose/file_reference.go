@@ -0,0 +1,130 @@
+package dockercompose
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/compose-spec/compose-go/types"
+)
+
+// FileReference represents a secret or a config mounted inside a service's
+// container.
+type FileReference struct {
+	// name is the name of the top-level secret or config.
+	name string
+	// target is the absolute path of the file inside the container.
+	target string
+	// uid is the user owning the file, empty for the container's user.
+	uid string
+	// gid is the group owning the file, empty for the container's user.
+	gid string
+	// mode is the permissions of the file, 0 for the default ones.
+	mode int
+	// file is the path of the referenced file in the host's directory, empty
+	// if it's not backed by a file.
+	file string
+	// environment is the name of the variable holding the content, empty if
+	// it's not backed by a variable.
+	environment string
+	// environmentValue is the value of the environment variable in the
+	// project's environment, nil if it's not set.
+	environmentValue *string
+	// content is the inline content of a config.
+	content string
+}
+
+// newFileReference merges the service's reference with its top-level
+// definition.
+//
+// A relative target is resolved from baseDir.
+func newFileReference(d *DockerCompose, ref types.FileReferenceConfig, definition types.FileObjectConfig, baseDir string) *FileReference {
+	target := ref.Target
+	if target == "" {
+		target = ref.Source
+	}
+
+	if !path.IsAbs(target) {
+		target = path.Join(baseDir, target)
+	}
+
+	fileRef := &FileReference{
+		name:        ref.Source,
+		target:      target,
+		uid:         ref.UID,
+		gid:         ref.GID,
+		environment: definition.Environment,
+		content:     definition.Content,
+	}
+
+	if value, exist := d.project.Environment[definition.Environment]; definition.Environment != "" && exist {
+		fileRef.environmentValue = &value
+	}
+
+	if ref.Mode != nil {
+		fileRef.mode = int(*ref.Mode)
+	}
+
+	if definition.File != "" && !bool(definition.External.External) {
+		fileRef.file = trimHostPath(d.workingDir, definition.File)
+	}
+
+	return fileRef
+}
+
+// Name returns the name of the top-level secret or config.
+func (f *FileReference) Name() string {
+	return f.name
+}
+
+// Target returns the absolute path of the file inside the container.
+func (f *FileReference) Target() string {
+	return f.target
+}
+
+// Owner returns the "uid:gid" owner of the file, empty for the container's
+// user.
+func (f *FileReference) Owner() string {
+	switch {
+	case f.uid != "" && f.gid != "":
+		return fmt.Sprintf("%s:%s", f.uid, f.gid)
+	case f.uid != "":
+		return f.uid
+	case f.gid != "":
+		return fmt.Sprintf("0:%s", f.gid)
+	}
+
+	return ""
+}
+
+// Mode returns the permissions of the file, 0 for the default ones.
+func (f *FileReference) Mode() int {
+	return f.mode
+}
+
+// File returns the path of the referenced file in the host's directory,
+// empty if it's not backed by a file.
+func (f *FileReference) File() string {
+	return f.file
+}
+
+// Environment returns the name of the variable holding the content, empty if
+// it's not backed by a variable.
+func (f *FileReference) Environment() string {
+	return f.environment
+}
+
+// Content returns the inline content of a config, empty if it's not an
+// inline config.
+func (f *FileReference) Content() string {
+	return f.content
+}
+
+// EnvironmentValue returns the value of the variable holding the content
+// in the project's environment, and true if it's set.
+func (f *FileReference) EnvironmentValue() (string, bool) {
+	if f.environmentValue == nil {
+		return "", false
+	}
+
+	return *f.environmentValue, true
+}
@@ -0,0 +1,124 @@
+package dockercompose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphFormat is the text format of a dependency graph.
+type GraphFormat string
+
+const (
+	GraphFormatMermaid GraphFormat = "mermaid"
+	GraphFormatDot     GraphFormat = "dot"
+)
+
+// checkCycles returns an error naming the first dependency cycle found
+// between the services, whatever their profiles.
+func (d *DockerCompose) checkCycles() error {
+	names := []string{}
+	for _, service := range d.project.AllServices() {
+		names = append(names, service.Name)
+	}
+	sort.Strings(names)
+
+	visited := map[string]bool{}
+	for _, name := range names {
+		if err := d.checkCyclesFrom(name, visited, []string{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCyclesFrom walks the dependencies of the service depth first.
+//
+// Path holds the services being walked to detect cycles, visited the services
+// whose dependencies are known to be acyclic.
+func (d *DockerCompose) checkCyclesFrom(name string, visited map[string]bool, path []string) error {
+	for i, walked := range path {
+		if walked == name {
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path[i:], name), " -> "))
+		}
+	}
+
+	if visited[name] {
+		return nil
+	}
+
+	service, err := d.GetService(name)
+	if err != nil {
+		// Unknown dependencies are reported by the loader.
+		return nil
+	}
+
+	for _, dependency := range service.Dependencies() {
+		if err := d.checkCyclesFrom(dependency.Name(), visited, append(path, name)); err != nil {
+			return err
+		}
+	}
+
+	visited[name] = true
+
+	return nil
+}
+
+// Graph returns the dependency graph of the services as Mermaid or DOT text.
+//
+// Edges go from a service to the services it depends on and are labelled by
+// their condition unless it's the default one.
+func (d *DockerCompose) Graph(format GraphFormat) (string, error) {
+	services := d.Services()
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name() < services[j].Name()
+	})
+
+	var graph strings.Builder
+
+	switch format {
+	case GraphFormatMermaid:
+		graph.WriteString("graph TD\n")
+
+		// Service names may contain characters Mermaid doesn't allow in
+		// identifiers, nodes are identified by their index instead.
+		ids := map[string]string{}
+		for i, service := range services {
+			ids[service.Name()] = fmt.Sprintf("n%d", i)
+			fmt.Fprintf(&graph, "  %s[%q]\n", ids[service.Name()], service.Name())
+		}
+
+		for _, service := range services {
+			for _, dependency := range service.Dependencies() {
+				if dependency.Condition() == DependencyConditionStarted {
+					fmt.Fprintf(&graph, "  %s --> %s\n", ids[service.Name()], ids[dependency.Name()])
+				} else {
+					fmt.Fprintf(&graph, "  %s -->|%s| %s\n", ids[service.Name()], dependency.Condition(), ids[dependency.Name()])
+				}
+			}
+		}
+	case GraphFormatDot:
+		graph.WriteString("digraph compose {\n")
+
+		for _, service := range services {
+			fmt.Fprintf(&graph, "  %q;\n", service.Name())
+		}
+
+		for _, service := range services {
+			for _, dependency := range service.Dependencies() {
+				if dependency.Condition() == DependencyConditionStarted {
+					fmt.Fprintf(&graph, "  %q -> %q;\n", service.Name(), dependency.Name())
+				} else {
+					fmt.Fprintf(&graph, "  %q -> %q [label=%q];\n", service.Name(), dependency.Name(), dependency.Condition())
+				}
+			}
+		}
+
+		graph.WriteString("}\n")
+	default:
+		return "", fmt.Errorf("unsupported graph format %s", format)
+	}
+
+	return graph.String(), nil
+}
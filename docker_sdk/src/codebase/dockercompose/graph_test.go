@@ -0,0 +1,179 @@
+package dockercompose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "no cycle",
+			content: `
+services:
+  a:
+    image: alpine
+    depends_on: [b, c]
+  b:
+    image: alpine
+    depends_on: [c]
+  c:
+    image: alpine
+`,
+		},
+		{
+			name: "two services",
+			content: `
+services:
+  a:
+    image: alpine
+    depends_on: [b]
+  b:
+    image: alpine
+    depends_on: [a]
+`,
+			wantErr: "dependency cycle detected: a -> b -> a",
+		},
+		{
+			name: "three services",
+			content: `
+services:
+  a:
+    image: alpine
+    depends_on: [b]
+  b:
+    image: alpine
+    depends_on: [c]
+  c:
+    image: alpine
+    depends_on: [a]
+`,
+			wantErr: "dependency cycle detected: a -> b -> c -> a",
+		},
+		{
+			name: "cycle behind a profile",
+			content: `
+services:
+  a:
+    image: alpine
+  b:
+    image: alpine
+    profiles: [debug]
+    depends_on: [c]
+  c:
+    image: alpine
+    profiles: [debug]
+    depends_on: [b]
+`,
+			wantErr: "dependency cycle detected: b -> c -> b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestDockerCompose(t, tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGraph(t *testing.T) {
+	content := `
+services:
+  web-app:
+    image: alpine
+    depends_on:
+      api:
+        condition: service_started
+  api:
+    image: alpine
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    image: alpine
+  web_app:
+    image: alpine
+    depends_on: [db]
+`
+
+	tests := []struct {
+		name    string
+		format  GraphFormat
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "mermaid",
+			format: GraphFormatMermaid,
+			want: `graph TD
+  n0["api"]
+  n1["db"]
+  n2["web-app"]
+  n3["web_app"]
+  n0 -->|service_healthy| n1
+  n2 --> n0
+  n3 --> n1
+`,
+		},
+		{
+			name:   "dot",
+			format: GraphFormatDot,
+			want: `digraph compose {
+  "api";
+  "db";
+  "web-app";
+  "web_app";
+  "api" -> "db" [label="service_healthy"];
+  "web-app" -> "api";
+  "web_app" -> "db";
+}
+`,
+		},
+		{
+			name:    "unsupported format",
+			format:  GraphFormat("svg"),
+			wantErr: true,
+		},
+	}
+
+	compose, err := newTestDockerCompose(t, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compose.Graph(tt.format)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Graph() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
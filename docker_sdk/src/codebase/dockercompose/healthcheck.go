@@ -0,0 +1,107 @@
+package dockercompose
+
+import (
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+)
+
+// Default healthcheck options, the same as Docker.
+const (
+	defaultHealthcheckInterval = 30 * time.Second
+	defaultHealthcheckTimeout  = 30 * time.Second
+	defaultHealthcheckRetries  = 3
+)
+
+// Healthcheck represents the healthcheck of a service.
+type Healthcheck struct {
+	// test is the command run to check the service's health.
+	test []string
+	// interval is the time between two checks.
+	interval time.Duration
+	// timeout is the time after which a check is considered failed.
+	timeout time.Duration
+	// startPeriod is the time during which failed checks don't count.
+	startPeriod time.Duration
+	// retries is the number of consecutive failed checks after which the
+	// service is unhealthy.
+	retries int
+}
+
+// newHealthcheck converts a compose healthcheck configuration, it returns nil
+// if the healthcheck is disabled or has no test, including a `CMD` or
+// `CMD-SHELL` test without command.
+func newHealthcheck(config *types.HealthCheckConfig) *Healthcheck {
+	if config == nil || config.Disable || len(config.Test) == 0 {
+		return nil
+	}
+
+	healthcheck := &Healthcheck{
+		interval: defaultHealthcheckInterval,
+		timeout:  defaultHealthcheckTimeout,
+		retries:  defaultHealthcheckRetries,
+	}
+
+	switch config.Test[0] {
+	case "NONE":
+		return nil
+	case "CMD":
+		if len(config.Test) < 2 {
+			return nil
+		}
+
+		healthcheck.test = config.Test[1:]
+	case "CMD-SHELL":
+		if len(config.Test) < 2 {
+			return nil
+		}
+
+		healthcheck.test = []string{"/bin/sh", "-c", config.Test[1]}
+	default:
+		healthcheck.test = config.Test
+	}
+
+	if config.Interval != nil {
+		healthcheck.interval = time.Duration(*config.Interval)
+	}
+
+	if config.Timeout != nil {
+		healthcheck.timeout = time.Duration(*config.Timeout)
+	}
+
+	if config.StartPeriod != nil {
+		healthcheck.startPeriod = time.Duration(*config.StartPeriod)
+	}
+
+	if config.Retries != nil {
+		healthcheck.retries = int(*config.Retries)
+	}
+
+	return healthcheck
+}
+
+// Test returns the command run to check the service's health.
+func (h *Healthcheck) Test() []string {
+	return h.test
+}
+
+// Interval returns the time between two checks.
+func (h *Healthcheck) Interval() time.Duration {
+	return h.interval
+}
+
+// Timeout returns the time after which a check is considered failed.
+func (h *Healthcheck) Timeout() time.Duration {
+	return h.timeout
+}
+
+// StartPeriod returns the time during which failed checks don't count.
+func (h *Healthcheck) StartPeriod() time.Duration {
+	return h.startPeriod
+}
+
+// Retries returns the number of consecutive failed checks after which the
+// service is unhealthy.
+func (h *Healthcheck) Retries() int {
+	return h.retries
+}
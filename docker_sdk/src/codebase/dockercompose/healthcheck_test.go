@@ -0,0 +1,72 @@
+package dockercompose
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/types"
+)
+
+func TestNewHealthcheck(t *testing.T) {
+	interval := types.Duration(5 * time.Second)
+	retries := uint64(10)
+
+	tests := []struct {
+		name         string
+		config       *types.HealthCheckConfig
+		wantTest     []string
+		wantInterval time.Duration
+		wantRetries  int
+		wantNil      bool
+	}{
+		{name: "no healthcheck", config: nil, wantNil: true},
+		{name: "disabled", config: &types.HealthCheckConfig{Test: []string{"CMD", "true"}, Disable: true}, wantNil: true},
+		{name: "none", config: &types.HealthCheckConfig{Test: []string{"NONE"}}, wantNil: true},
+		{name: "cmd without command", config: &types.HealthCheckConfig{Test: []string{"CMD"}}, wantNil: true},
+		{name: "cmd-shell without command", config: &types.HealthCheckConfig{Test: []string{"CMD-SHELL"}}, wantNil: true},
+		{
+			name:         "cmd",
+			config:       &types.HealthCheckConfig{Test: []string{"CMD", "pg_isready", "-h", "localhost"}},
+			wantTest:     []string{"pg_isready", "-h", "localhost"},
+			wantInterval: defaultHealthcheckInterval,
+			wantRetries:  defaultHealthcheckRetries,
+		},
+		{
+			name:         "cmd-shell",
+			config:       &types.HealthCheckConfig{Test: []string{"CMD-SHELL", "redis-cli ping || exit 1"}, Interval: &interval, Retries: &retries},
+			wantTest:     []string{"/bin/sh", "-c", "redis-cli ping || exit 1"},
+			wantInterval: 5 * time.Second,
+			wantRetries:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthcheck := newHealthcheck(tt.config)
+			if tt.wantNil {
+				if healthcheck != nil {
+					t.Fatalf("newHealthcheck() = %+v, want nil", healthcheck)
+				}
+
+				return
+			}
+
+			if healthcheck == nil {
+				t.Fatal("newHealthcheck() = nil")
+			}
+
+			if !slices.Equal(healthcheck.Test(), tt.wantTest) {
+				t.Errorf("Test() = %q, want %q", healthcheck.Test(), tt.wantTest)
+			}
+
+			if healthcheck.Interval() != tt.wantInterval {
+				t.Errorf("Interval() = %s, want %s", healthcheck.Interval(), tt.wantInterval)
+			}
+
+			if healthcheck.Retries() != tt.wantRetries {
+				t.Errorf("Retries() = %d, want %d", healthcheck.Retries(), tt.wantRetries)
+			}
+		})
+	}
+}
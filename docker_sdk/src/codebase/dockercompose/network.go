@@ -0,0 +1,91 @@
+package dockercompose
+
+import (
+	"slices"
+	"sort"
+)
+
+// defaultNetwork is the network services are attached to if they don't
+// declare any.
+const defaultNetwork = "default"
+
+// Networks returns the sorted names of the networks the service is attached
+// to.
+func (s *Service) Networks() []string {
+	networks := []string{}
+	for name := range s.s.Networks {
+		networks = append(networks, name)
+	}
+
+	if len(networks) == 0 {
+		return []string{defaultNetwork}
+	}
+
+	sort.Strings(networks)
+
+	return networks
+}
+
+// SharedNetworks returns the sorted names of the networks both services are
+// attached to.
+func (s *Service) SharedNetworks(other *Service) []string {
+	otherNetworks := other.Networks()
+
+	shared := []string{}
+	for _, network := range s.Networks() {
+		if slices.Contains(otherNetworks, network) {
+			shared = append(shared, network)
+		}
+	}
+
+	return shared
+}
+
+// CanReach returns true if the service can reach the other service over at
+// least one shared network.
+func (s *Service) CanReach(other *Service) bool {
+	return len(s.SharedNetworks(other)) != 0
+}
+
+// AliasesFor returns the hostnames the service is reachable at from the
+// other service besides its name: its container name, hostname and the
+// aliases declared on the networks they share, sorted and without
+// duplicates.
+//
+// It returns nil if the services don't share any network.
+func (s *Service) AliasesFor(other *Service) []string {
+	shared := s.SharedNetworks(other)
+	if len(shared) == 0 {
+		return nil
+	}
+
+	return s.aliasesOn(shared)
+}
+
+// aliasesOn returns the hostnames the service is reachable at on the given
+// networks besides its name: its container name, hostname and the aliases
+// declared on these networks, sorted and without duplicates.
+func (s *Service) aliasesOn(networks []string) []string {
+	aliases := []string{}
+
+	if s.s.ContainerName != "" {
+		aliases = append(aliases, s.s.ContainerName)
+	}
+
+	if s.s.Hostname != "" {
+		aliases = append(aliases, s.s.Hostname)
+	}
+
+	for _, network := range networks {
+		if config := s.s.Networks[network]; config != nil {
+			aliases = append(aliases, config.Aliases...)
+		}
+	}
+
+	aliases = slices.DeleteFunc(aliases, func(alias string) bool {
+		return alias == s.s.Name
+	})
+	slices.Sort(aliases)
+
+	return slices.Compact(aliases)
+}
@@ -0,0 +1,132 @@
+package dockercompose
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestServiceNetworks(t *testing.T) {
+	content := `
+services:
+  front:
+    image: alpine
+    networks:
+      - public
+  api:
+    image: alpine
+    container_name: api-container
+    hostname: api-host
+    networks:
+      public:
+        aliases:
+          - backend
+          - api
+      private:
+        aliases:
+          - internal
+          - backend
+  db:
+    image: alpine
+    networks:
+      - private
+  worker:
+    image: alpine
+  cache:
+    image: alpine
+networks:
+  public:
+  private:
+`
+
+	compose, err := newTestDockerCompose(t, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := func(name string) *Service {
+		service, err := compose.GetService(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return service
+	}
+
+	tests := []struct {
+		name        string
+		service     string
+		other       string
+		wantShared  []string
+		wantReach   bool
+		wantAliases []string
+	}{
+		{
+			name:        "shared network",
+			service:     "api",
+			other:       "front",
+			wantShared:  []string{"public"},
+			wantReach:   true,
+			wantAliases: []string{"api-container", "api-host", "backend"},
+		},
+		{
+			name:        "other shared network",
+			service:     "api",
+			other:       "db",
+			wantShared:  []string{"private"},
+			wantReach:   true,
+			wantAliases: []string{"api-container", "api-host", "backend", "internal"},
+		},
+		{
+			name:       "no shared network",
+			service:    "front",
+			other:      "db",
+			wantShared: []string{},
+			wantReach:  false,
+		},
+		{
+			name:       "default network isn't shared with declared networks",
+			service:    "worker",
+			other:      "api",
+			wantShared: []string{},
+			wantReach:  false,
+		},
+		{
+			name:        "default network",
+			service:     "worker",
+			other:       "cache",
+			wantShared:  []string{"default"},
+			wantReach:   true,
+			wantAliases: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, other := service(tt.service), service(tt.other)
+
+			if got := s.SharedNetworks(other); !slices.Equal(got, tt.wantShared) {
+				t.Errorf("SharedNetworks() = %v, want %v", got, tt.wantShared)
+			}
+
+			if got := s.CanReach(other); got != tt.wantReach {
+				t.Errorf("CanReach() = %v, want %v", got, tt.wantReach)
+			}
+
+			if got := s.AliasesFor(other); !slices.Equal(got, tt.wantAliases) {
+				t.Errorf("AliasesFor() = %v, want %v", got, tt.wantAliases)
+			}
+		})
+	}
+
+	if got, want := service("api").Networks(), []string{"private", "public"}; !slices.Equal(got, want) {
+		t.Errorf("Networks() = %v, want %v", got, want)
+	}
+
+	if got, want := service("worker").Networks(), []string{"default"}; !slices.Equal(got, want) {
+		t.Errorf("Networks() = %v, want %v", got, want)
+	}
+
+	if got, want := service("api").Aliases(), []string{"api-container", "api-host", "backend", "internal"}; !slices.Equal(got, want) {
+		t.Errorf("Aliases() = %v, want %v", got, want)
+	}
+}
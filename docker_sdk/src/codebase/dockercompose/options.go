@@ -0,0 +1,143 @@
+package dockercompose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// Tmpfs represents a temporary directory mounted inside a container.
+type Tmpfs struct {
+	// path is the location where the directory is mounted inside the
+	// container.
+	path string
+	// size is the size of the directory in bytes, 0 if unlimited.
+	size int
+}
+
+// Path returns the path to mount inside the container.
+func (t *Tmpfs) Path() string {
+	return t.path
+}
+
+// Size returns the size of the directory in bytes, 0 if unlimited.
+func (t *Tmpfs) Size() int {
+	return t.size
+}
+
+// User returns the user the service's process runs as, empty for the image's
+// user.
+func (s *Service) User() string {
+	return s.s.User
+}
+
+// Hostname returns the hostname of the service, empty if not set.
+func (s *Service) Hostname() string {
+	return s.s.Hostname
+}
+
+// Aliases returns the hostnames the service is reachable at besides its name:
+// its container name, hostname and network aliases, sorted and without
+// duplicates.
+func (s *Service) Aliases() []string {
+	return s.aliasesOn(s.Networks())
+}
+
+// Privileged returns true if the service runs with extended privileges.
+//
+// Added capabilities are ignored since they cannot be granted one by one,
+// only `privileged` grants all root capabilities.
+func (s *Service) Privileged() bool {
+	return s.s.Privileged
+}
+
+// Labels returns the labels of the service.
+func (s *Service) Labels() map[string]string {
+	return s.s.Labels
+}
+
+// Tmpfs returns the temporary directories of the service, declared with
+// `tmpfs`, `tmpfs` volumes and `shm_size`.
+func (s *Service) Tmpfs() []*Tmpfs {
+	tmpfs := []*Tmpfs{}
+
+	for _, entry := range s.s.Tmpfs {
+		// Entries may have options, e.g., "/run:rw,size=64k".
+		path, options, _ := strings.Cut(entry, ":")
+
+		size := 0
+		for _, option := range strings.Split(options, ",") {
+			value, found := strings.CutPrefix(option, "size=")
+			if !found {
+				continue
+			}
+
+			bytes, err := units.RAMInBytes(value)
+			if err != nil {
+				fmt.Printf("invalid tmpfs size %s of service %s, ignoring it\n", value, s.Name())
+
+				continue
+			}
+
+			size = int(bytes)
+		}
+
+		tmpfs = append(tmpfs, &Tmpfs{path: path, size: size})
+	}
+
+	for _, v := range s.s.Volumes {
+		if v.Type != "tmpfs" {
+			continue
+		}
+
+		size := 0
+		if v.Tmpfs != nil {
+			size = int(v.Tmpfs.Size)
+		}
+
+		tmpfs = append(tmpfs, &Tmpfs{path: v.Target, size: size})
+	}
+
+	if s.s.ShmSize != 0 {
+		tmpfs = append(tmpfs, &Tmpfs{path: "/dev/shm", size: int(s.s.ShmSize)})
+	}
+
+	return tmpfs
+}
+
+// Warnings returns a message for each property of the service that cannot
+// be represented in Dagger, sorted.
+func (s *Service) Warnings() []string {
+	warnings := []string{}
+
+	unsupported := map[string]bool{
+		"extra_hosts":       len(s.s.ExtraHosts) != 0,
+		"read_only":         s.s.ReadOnly,
+		"stop_signal":       s.s.StopSignal != "",
+		"stop_grace_period": s.s.StopGracePeriod != nil,
+		"cap_drop":          len(s.s.CapDrop) != 0,
+		"devices":           len(s.s.Devices) != 0,
+		"dns":               len(s.s.DNS) != 0,
+		"network_mode":      s.s.NetworkMode != "",
+		"pid":               s.s.Pid != "",
+		"security_opt":      len(s.s.SecurityOpt) != 0,
+		"sysctls":           len(s.s.Sysctls) != 0,
+		"ulimits":           len(s.s.Ulimits) != 0,
+	}
+
+	for property, set := range unsupported {
+		if set {
+			warnings = append(warnings, fmt.Sprintf("%s is not supported by Dagger, ignoring it", property))
+		}
+	}
+
+	if len(s.s.CapAdd) != 0 && !s.s.Privileged {
+		warnings = append(warnings, fmt.Sprintf("cap_add (%s) cannot be granted one by one, ignoring it ; set privileged to run with all root capabilities", strings.Join(s.s.CapAdd, ", ")))
+	}
+
+	sort.Strings(warnings)
+
+	return warnings
+}
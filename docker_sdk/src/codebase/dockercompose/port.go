@@ -0,0 +1,127 @@
+package dockercompose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// ProxyExtension is the extension of a service or a port setting how the
+// proxy forwards its ports: `http` or `tcp`.
+const ProxyExtension = "x-dagger-proxy"
+
+// PortProtocol is the transport protocol of a port.
+type PortProtocol string
+
+const (
+	PortProtocolTcp PortProtocol = "tcp"
+	PortProtocolUdp PortProtocol = "udp"
+)
+
+// Port represents a port of a service and the port it's published on.
+type Port struct {
+	// published is the port exposed to the host, it's the same as target
+	// if the port isn't published.
+	published int
+
+	// target is the port the service's container listens on.
+	target int
+
+	// protocol is the transport protocol of the port.
+	protocol PortProtocol
+
+	// http is true if the port serves HTTP and should be proxied as such.
+	http bool
+}
+
+// Published returns the port exposed to the host.
+func (p *Port) Published() int {
+	return p.published
+}
+
+// Target returns the port the service's container listens on.
+func (p *Port) Target() int {
+	return p.target
+}
+
+// Protocol returns the transport protocol of the port.
+func (p *Port) Protocol() PortProtocol {
+	return p.protocol
+}
+
+// Http returns true if the port serves HTTP, it's always false for UDP
+// ports.
+func (p *Port) Http() bool {
+	return p.http && p.protocol == PortProtocolTcp
+}
+
+// key returns a unique key of the port for the given number and protocol.
+func (p *Port) key(port int) string {
+	return fmt.Sprintf("%d/%s", port, p.protocol)
+}
+
+// removePortDuplicates removes the ports using the same number, returned by
+// number, and protocol as a previous one.
+func removePortDuplicates(ports []*Port, number func(*Port) int) []*Port {
+	seen := map[string]bool{}
+
+	result := []*Port{}
+	for _, port := range ports {
+		key := port.key(number(port))
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		result = append(result, port)
+	}
+
+	return result
+}
+
+// parsePortProtocol returns the protocol matching the given value, TCP by
+// default (including for `http`).
+func parsePortProtocol(protocol string) PortProtocol {
+	if strings.EqualFold(protocol, string(PortProtocolUdp)) {
+		return PortProtocolUdp
+	}
+
+	return PortProtocolTcp
+}
+
+// proxyMode returns the `x-dagger-proxy` extension value of the given
+// extensions, empty if unset.
+func proxyMode(extensions map[string]interface{}) string {
+	mode, ok := extensions[ProxyExtension].(string)
+	if !ok {
+		return ""
+	}
+
+	return strings.ToLower(mode)
+}
+
+// parseExposedPorts parses an `expose` entry (e.g., `3000`, `4000-4010/udp`)
+// into one port per number of the range.
+func parseExposedPorts(expose string) ([]*Port, error) {
+	protocol, rawPort := nat.SplitProtoPort(expose)
+	if rawPort == "" {
+		return nil, fmt.Errorf("invalid exposed port %q", expose)
+	}
+
+	start, end, err := nat.ParsePortRangeToInt(rawPort)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := []*Port{}
+	for port := start; port <= end; port++ {
+		ports = append(ports, &Port{
+			published: port,
+			target:    port,
+			protocol:  parsePortProtocol(protocol),
+		})
+	}
+
+	return ports, nil
+}
@@ -0,0 +1,192 @@
+package dockercompose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"dagger.io/dockersdk/codebase/finder"
+	"github.com/compose-spec/compose-go/types"
+)
+
+// newTestDockerCompose loads a Docker Compose project from the given
+// content.
+func newTestDockerCompose(t *testing.T, content string) (*DockerCompose, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	finder, err := finder.New(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newDockerCompose(context.Background(), dir, map[string]string{}, []types.ConfigFile{
+		{Filename: "docker-compose.yml", Content: []byte(content)},
+	}, finder)
+}
+
+// mustGetService loads the given content and returns the service of the
+// given name.
+func mustGetService(t *testing.T, content string, name string) *Service {
+	t.Helper()
+
+	compose, err := newTestDockerCompose(t, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := compose.GetService(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service
+}
+
+// formatPorts formats the ports as `published:target/protocol`, suffixed by
+// `+http` for HTTP ports.
+func formatPorts(ports []*Port) string {
+	formatted := []string{}
+	for _, port := range ports {
+		entry := fmt.Sprintf("%d:%d/%s", port.Published(), port.Target(), port.Protocol())
+		if port.Http() {
+			entry += "+http"
+		}
+
+		formatted = append(formatted, entry)
+	}
+
+	return strings.Join(formatted, " ")
+}
+
+func TestParseExposedPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		expose  string
+		want    string
+		wantErr bool
+	}{
+		{name: "single port", expose: "3000", want: "3000:3000/tcp"},
+		{name: "udp port", expose: "53/udp", want: "53:53/udp"},
+		{name: "range", expose: "4000-4002/udp", want: "4000:4000/udp 4001:4001/udp 4002:4002/udp"},
+		{name: "invalid range", expose: "4002-4000", wantErr: true},
+		{name: "not a number", expose: "http", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, err := parseExposedPorts(tt.expose)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", formatPorts(ports))
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := formatPorts(ports); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServicePorts(t *testing.T) {
+	tests := []struct {
+		name          string
+		service       string
+		exposed       []int
+		wantPorts     string
+		wantPublished string
+	}{
+		{
+			name: "published ports",
+			service: `
+    ports:
+      - "8080:80"
+      - "9000-9002:9000"
+      - "53:53/udp"
+      - "443"`,
+			wantPorts:     "80:80/tcp 9000:9000/tcp 53:53/udp 443:443/tcp",
+			wantPublished: "8080:80/tcp 9000:9000/tcp 53:53/udp 443:443/tcp",
+		},
+		{
+			name: "exposed ports are published without published ports",
+			service: `
+    expose:
+      - "3000"
+      - "4000-4001/udp"`,
+			wantPorts:     "3000:3000/tcp 4000:4000/udp 4001:4001/udp",
+			wantPublished: "3000:3000/tcp 4000:4000/udp 4001:4001/udp",
+		},
+		{
+			name: "ports exposed by the image",
+			service: `
+    expose:
+      - "3000"`,
+			exposed:       []int{3000, 5000},
+			wantPorts:     "3000:3000/tcp 5000:5000/tcp",
+			wantPublished: "3000:3000/tcp 5000:5000/tcp",
+		},
+		{
+			name: "duplicates",
+			service: `
+    ports:
+      - "8080:80"
+      - "8080:81"
+      - "8081:80"
+    expose:
+      - "80"`,
+			wantPorts:     "80:80/tcp 81:81/tcp",
+			wantPublished: "8080:80/tcp 8081:80/tcp",
+		},
+		{
+			name: "same number with another protocol",
+			service: `
+    ports:
+      - "53:53/tcp"
+      - "53:53/udp"`,
+			wantPorts:     "53:53/tcp 53:53/udp",
+			wantPublished: "53:53/tcp 53:53/udp",
+		},
+		{
+			name: "http ports",
+			service: `
+    ports:
+      - "8080:80"
+      - target: 9000
+        published: "9000"
+        x-dagger-proxy: http
+      - target: 53
+        published: "53"
+        protocol: udp
+        x-dagger-proxy: http`,
+			wantPorts:     "80:80/tcp 9000:9000/tcp 53:53/udp",
+			wantPublished: "8080:80/tcp 9000:9000/tcp+http 53:53/udp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := mustGetService(t, "services:\n  api:\n    image: alpine"+tt.service+"\n", "api")
+
+			for _, port := range tt.exposed {
+				service.WithExposedPort(port, PortProtocolTcp)
+			}
+
+			if got := formatPorts(service.Ports()); got != tt.wantPorts {
+				t.Errorf("Ports() = %q, want %q", got, tt.wantPorts)
+			}
+
+			if got := formatPorts(service.PublishedPorts()); got != tt.wantPublished {
+				t.Errorf("PublishedPorts() = %q, want %q", got, tt.wantPublished)
+			}
+		})
+	}
+}
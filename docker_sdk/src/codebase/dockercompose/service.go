@@ -2,11 +2,12 @@ package dockercompose
 
 import (
 	"fmt"
-	"strconv"
+	"slices"
+	"strings"
 
 	"dagger.io/dockersdk/codebase/finder"
-	"dagger.io/dockersdk/utils"
 	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/go-connections/nat"
 )
 
 // Service represents a Docker Compose service configuration.
@@ -23,7 +24,7 @@ type Service struct {
 	// exposedPorts holds additional ports exposed by the service's image.
 	//
 	// These can only be retrieved after the service's image has been pulled.
-	exposedPorts []int
+	exposedPorts []*Port
 }
 
 func NewService(sourceCompose *DockerCompose, service *types.ServiceConfig, finder *finder.Finder) *Service {
@@ -47,6 +48,12 @@ func (s *Service) ContainerName() string {
 	return s.s.Name
 }
 
+// Profiles returns the profiles the service belongs to, empty if the service
+// is always enabled.
+func (s *Service) Profiles() []string {
+	return s.s.Profiles
+}
+
 // Source returns the source of the service, either image or Dockerfile.
 //
 // If the service is not defined in the Compose file, this will leads to a panic.
@@ -63,7 +70,8 @@ func (s *Service) Source() *Source {
 	if s.s.Build != nil {
 		dockerfile := &SourceDockerfile{
 			Dockerfile: s.s.Build.Dockerfile,
-			Context:    trimHostPath(s.s.Build.Context),
+			Context:    trimHostPath(s.sourceCompose.workingDir, s.s.Build.Context),
+			BuildArgs:  map[string]*string{},
 		}
 
 		if s.s.Build.Args != nil {
@@ -90,41 +98,105 @@ func (s *Service) Workdir() string {
 	return s.s.WorkingDir
 }
 
-// Ports returns all published and exposed ports for the service.
-func (s *Service) Ports() []int {
-	ports := []int{}
+// Ports returns the ports the service's container listens on: the target of
+// published ports, exposed ports and ports exposed by its image.
+func (s *Service) Ports() []*Port {
+	ports := []*Port{}
 
 	for _, port := range s.s.Ports {
-		published, err := strconv.Atoi(port.Published)
+		ports = append(ports, &Port{
+			published: int(port.Target),
+			target:    int(port.Target),
+			protocol:  parsePortProtocol(port.Protocol),
+		})
+	}
+
+	for _, expose := range s.s.Expose {
+		exposed, err := parseExposedPorts(expose)
 		if err != nil {
-			fmt.Println(fmt.Errorf("failed to parse port published: %w, ignoring it", err))
+			fmt.Println(fmt.Errorf("failed to parse exposed port: %w, ignoring it", err))
+
+			continue
 		}
 
-		ports = append(ports, published)
+		ports = append(ports, exposed...)
 	}
 
-	for _, port := range s.s.Expose {
-		published, err := strconv.Atoi(port)
-		if err != nil {
-			fmt.Println(fmt.Errorf("failed to parse port published: %w, ignoring it", err))
+	ports = append(ports, s.exposedPorts...)
+
+	return removePortDuplicates(ports, (*Port).Target)
+}
+
+// PublishedPorts returns the ports published by the service with the port
+// they're mapped to in the container.
+//
+// A range of published ports mapped to a single target port is published on
+// the first port of the range, a port without published port is published
+// on its target.
+// If the service doesn't publish any port, its exposed ports are published
+// as is.
+//
+// A port is proxied as HTTP if its protocol is `http` or if its
+// `x-dagger-proxy` extension, or else the service's one, is `http`.
+func (s *Service) PublishedPorts() []*Port {
+	serviceHttp := proxyMode(s.s.Extensions) == "http"
+
+	if len(s.s.Ports) == 0 {
+		ports := []*Port{}
+		for _, port := range s.Ports() {
+			ports = append(ports, &Port{
+				published: port.published,
+				target:    port.target,
+				protocol:  port.protocol,
+				http:      serviceHttp,
+			})
 		}
 
-		ports = append(ports, published)
+		return ports
 	}
 
-	for _, port := range s.exposedPorts {
-		ports = append(ports, port)
-	}
+	ports := []*Port{}
+	for _, port := range s.s.Ports {
+		published := int(port.Target)
+
+		if port.Published != "" {
+			start, _, err := nat.ParsePortRangeToInt(port.Published)
+			if err != nil {
+				fmt.Println(fmt.Errorf("failed to parse port published: %w, ignoring it", err))
+
+				continue
+			}
 
-	// Remove duplicates if there are any.
-	ports = utils.RemoveListDuplicates(ports)
+			published = start
+		}
+
+		http := serviceHttp
+		switch proxyMode(port.Extensions) {
+		case "http":
+			http = true
+		case "tcp":
+			http = false
+		}
 
-	return ports
+		ports = append(ports, &Port{
+			published: published,
+			target:    int(port.Target),
+			protocol:  parsePortProtocol(port.Protocol),
+			http:      http || strings.EqualFold(port.Protocol, "http"),
+		})
+	}
+
+	return removePortDuplicates(ports, (*Port).Published)
 }
 
 // WithExposedPort adds an additional exposed port to the service.
-func (s *Service) WithExposedPort(port int) *Service {
-	s.exposedPorts = append(s.exposedPorts, port)
+func (s *Service) WithExposedPort(port int, protocol PortProtocol) *Service {
+	s.exposedPorts = append(s.exposedPorts, &Port{
+		published: port,
+		target:    port,
+		protocol:  protocol,
+	})
+
 	return s
 }
 
@@ -145,17 +217,32 @@ func (s *Service) Environment() (env map[string]*string, secrets []string) {
 	return env, secrets
 }
 
-// MountedSecrets lists secrets mounted in the service configuration.
-func (s *Service) MountedSecrets() []string {
-	secrets := []string{}
+// MountedSecrets lists secrets mounted in the service configuration,
+// relative targets are resolved from `/run/secrets`.
+func (s *Service) MountedSecrets() []*FileReference {
+	secrets := []*FileReference{}
 
 	for _, secret := range s.s.Secrets {
-		secrets = append(secrets, secret.Source)
+		definition := types.FileObjectConfig(s.sourceCompose.project.Secrets[secret.Source])
+		secrets = append(secrets, newFileReference(s.sourceCompose, types.FileReferenceConfig(secret), definition, "/run/secrets"))
 	}
 
 	return secrets
 }
 
+// Configs returns the configs mounted in the service, relative targets are
+// resolved from the root directory.
+func (s *Service) Configs() []*FileReference {
+	configs := []*FileReference{}
+
+	for _, config := range s.s.Configs {
+		definition := types.FileObjectConfig(s.sourceCompose.project.Configs[config.Source])
+		configs = append(configs, newFileReference(s.sourceCompose, types.FileReferenceConfig(config), definition, "/"))
+	}
+
+	return configs
+}
+
 // Volumes returns all the volumes and caches used by the service.
 //
 // If a volume is not defined in the host's directory, it will be transformed
@@ -167,9 +254,9 @@ func (s *Service) Volumes() ([]*Volume, []*Cache) {
 	for _, v := range s.s.Volumes {
 		switch v.Type {
 		case "volume":
-			caches = append(caches, &Cache{name: v.Source, path: v.Target})
+			caches = append(caches, &Cache{name: v.Source, path: v.Target, named: true})
 		case "bind":
-			source := trimHostPath(v.Source)
+			source := trimHostPath(s.sourceCompose.workingDir, v.Source)
 
 			isDir, err := s.finder.IsPathDirectory(source)
 			if err != nil {
@@ -185,11 +272,57 @@ func (s *Service) Volumes() ([]*Volume, []*Cache) {
 	return volumes, caches
 }
 
-// DependsOn retrieves a list of services this service depends on.
+// Dependencies returns the services this service directly depends on, sorted
+// by name, with their condition.
+func (s *Service) Dependencies() []*Dependency {
+	dependencies := []*Dependency{}
+
+	for name, dependency := range s.s.DependsOn {
+		condition := DependencyCondition(dependency.Condition)
+		if condition == "" {
+			condition = DependencyConditionStarted
+		}
+
+		dependencies = append(dependencies, &Dependency{name: name, condition: condition})
+	}
+
+	slices.SortFunc(dependencies, func(a, b *Dependency) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return dependencies
+}
+
+// Healthcheck returns the healthcheck of the service, nil if it has none or
+// if it's disabled.
+func (s *Service) Healthcheck() *Healthcheck {
+	return newHealthcheck(s.s.HealthCheck)
+}
+
+// DependsOn retrieves a list of services this service depends on, directly
+// or not.
 func (s *Service) DependsOn() []string {
 	dependentServices := map[string]bool{}
+	s.collectDependsOn(dependentServices)
+
+	var dependentServicesList []string
+	for service := range dependentServices {
+		dependentServicesList = append(dependentServicesList, service)
+	}
 
+	return dependentServicesList
+}
+
+// collectDependsOn adds the services this service depends on, directly or
+// not, to dependentServices.
+//
+// Services already collected are skipped so cycles don't recurse forever.
+func (s *Service) collectDependsOn(dependentServices map[string]bool) {
 	for key := range s.s.DependsOn {
+		if dependentServices[key] {
+			continue
+		}
+
 		dependentServices[key] = true
 
 		service, err := s.sourceCompose.GetService(key)
@@ -199,18 +332,8 @@ func (s *Service) DependsOn() []string {
 			continue
 		}
 
-		serviceDeps := service.DependsOn()
-		for _, dep := range serviceDeps {
-			dependentServices[dep] = true
-		}
+		service.collectDependsOn(dependentServices)
 	}
-
-	var dependentServicesList []string
-	for service := range dependentServices {
-		dependentServicesList = append(dependentServicesList, service)
-	}
-
-	return dependentServicesList
 }
 
 // Entrypoint returns the entrypoint of the service, if defined.
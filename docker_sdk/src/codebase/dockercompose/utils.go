@@ -2,15 +2,18 @@ package dockercompose
 
 import (
 	"fmt"
-	"strings"
+	"path/filepath"
 )
 
-// For some reason, docker-compose prefix all relative paths with /scratch.
-// Because we work with the directory itself, we need to remove it.
-func trimHostPath(hostPath string) string {
-	if hostPath == "/scratch" {
+// The loader resolves all relative paths from the working directory of the
+// project.
+// Because we work with the directory itself, we need to make them relative
+// again.
+func trimHostPath(workingDir string, hostPath string) string {
+	relPath, err := filepath.Rel(workingDir, hostPath)
+	if err != nil || relPath == "." {
 		return "."
 	}
 
-	return fmt.Sprintf("./%s", strings.TrimPrefix(hostPath, "/scratch/"))
-}
\ No newline at end of file
+	return fmt.Sprintf("./%s", relPath)
+}
@@ -0,0 +1,98 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+// argScope holds the build arguments visible at a given point of the
+// Dockerfile.
+//
+// Arguments declared before the first FROM are global, they can only be
+// used inside a stage if the stage declares them again.
+type argScope struct {
+	// lex evaluates arguments values using the Dockerfile's escape token.
+	lex *shell.Lex
+
+	// global holds the arguments declared before the first FROM.
+	global map[string]string
+
+	// stage holds the arguments declared in the current stage.
+	//
+	// It's nil until the first FROM is reached.
+	stage map[string]string
+}
+
+// newArgScope creates an empty scope using the given escape token.
+func newArgScope(escapeToken rune) *argScope {
+	return &argScope{
+		lex:    shell.NewLex(escapeToken),
+		global: map[string]string{},
+	}
+}
+
+// enterStage resets the scope to a new stage.
+func (s *argScope) enterStage() {
+	s.stage = map[string]string{}
+}
+
+// current returns the arguments of the current scope.
+func (s *argScope) current() map[string]string {
+	if s.stage == nil {
+		return s.global
+	}
+
+	return s.stage
+}
+
+// Get implements the shell.EnvGetter interface.
+func (s *argScope) Get(key string) (string, bool) {
+	value, exist := s.current()[key]
+
+	return value, exist
+}
+
+// Keys implements the shell.EnvGetter interface.
+func (s *argScope) Keys() []string {
+	keys := []string{}
+	for key := range s.current() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// declare evaluates an ARG declaration (`NAME` or `NAME=value`) and adds it
+// to the current scope.
+//
+// The value is expanded against the arguments already in scope, so
+// `${VAR}` and `${VAR:-default}` are resolved and quotes are removed.
+// An argument declared without value inside a stage inherits the value of
+// the global argument of the same name, if any.
+//
+// Returns the argument's name and its effective value.
+func (s *argScope) declare(declaration string) (string, string, error) {
+	name, rawValue, hasValue := strings.Cut(declaration, "=")
+	if name == "" {
+		return "", "", fmt.Errorf("invalid ARG %q: missing name", declaration)
+	}
+
+	value := ""
+	switch {
+	case hasValue:
+		processed, _, err := s.lex.ProcessWord(rawValue, s)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid ARG %q: %w", declaration, err)
+		}
+
+		value = processed
+	case s.stage != nil:
+		value = s.global[name]
+	}
+
+	s.current()[name] = value
+
+	return name, value, nil
+}
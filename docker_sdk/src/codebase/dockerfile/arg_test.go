@@ -0,0 +1,202 @@
+package dockerfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestDockerfile parses the given Dockerfile content.
+func newTestDockerfile(t *testing.T, content string) *Dockerfile {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	dockerfile, err := NewDockerfile("Dockerfile", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dockerfile
+}
+
+func TestArgScopeDeclare(t *testing.T) {
+	tests := []struct {
+		name        string
+		global      []string
+		stage       []string
+		declaration string
+		wantName    string
+		wantValue   string
+		wantErr     bool
+	}{
+		{
+			name:        "without value",
+			declaration: "VERSION",
+			wantName:    "VERSION",
+		},
+		{
+			name:        "plain value",
+			declaration: "VERSION=1.2",
+			wantName:    "VERSION",
+			wantValue:   "1.2",
+		},
+		{
+			name:        "double quotes are removed",
+			declaration: `GREETING="hello world"`,
+			wantName:    "GREETING",
+			wantValue:   "hello world",
+		},
+		{
+			name:        "single quotes are removed without expansion",
+			declaration: `RAW='${VERSION}'`,
+			wantName:    "RAW",
+			wantValue:   "${VERSION}",
+		},
+		{
+			name:        "expands a previous argument",
+			global:      []string{"VERSION=1.2"},
+			declaration: "IMAGE=alpine:${VERSION}",
+			wantName:    "IMAGE",
+			wantValue:   "alpine:1.2",
+		},
+		{
+			name:        "default of an unset argument",
+			declaration: "IMAGE=alpine:${VERSION:-latest}",
+			wantName:    "IMAGE",
+			wantValue:   "alpine:latest",
+		},
+		{
+			name:        "global arguments are not visible in a stage",
+			global:      []string{"VERSION=1.2"},
+			stage:       []string{},
+			declaration: "IMAGE=alpine:${VERSION:-latest}",
+			wantName:    "IMAGE",
+			wantValue:   "alpine:latest",
+		},
+		{
+			name:        "redeclared global argument inherits its value",
+			global:      []string{"VERSION=1.2"},
+			stage:       []string{},
+			declaration: "VERSION",
+			wantName:    "VERSION",
+			wantValue:   "1.2",
+		},
+		{
+			name:        "stage arguments are visible in the stage",
+			global:      []string{"VERSION=1.2"},
+			stage:       []string{"VERSION"},
+			declaration: "IMAGE=alpine:${VERSION}",
+			wantName:    "IMAGE",
+			wantValue:   "alpine:1.2",
+		},
+		{
+			name:        "missing name",
+			declaration: "=value",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := newArgScope('\\')
+
+			for _, declaration := range tt.global {
+				if _, _, err := scope.declare(declaration); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.stage != nil {
+				scope.enterStage()
+
+				for _, declaration := range tt.stage {
+					if _, _, err := scope.declare(declaration); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			name, value, err := scope.declare(tt.declaration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("declare(%q) error = %v, wantErr %t", tt.declaration, err, tt.wantErr)
+			}
+
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("declare(%q) = %q, %q, want %q, %q", tt.declaration, name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestDockerfileArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name: "global and stage arguments",
+			content: `ARG BASE=alpine
+ARG VERSION=3.20
+FROM ${BASE}:${VERSION} AS build
+ARG VERSION
+ARG TARGET="${VERSION}-build"
+RUN echo $TARGET
+`,
+			want: map[string]string{
+				"BASE":    "alpine",
+				"VERSION": "3.20",
+				"TARGET":  "3.20-build",
+			},
+		},
+		{
+			name: "several arguments in one instruction",
+			content: `FROM alpine
+ARG A=1 B=${A}2
+`,
+			want: map[string]string{
+				"A": "1",
+				"B": "12",
+			},
+		},
+		{
+			name: "first non empty value is kept across stages",
+			content: `FROM alpine AS first
+ARG MODE
+FROM alpine AS second
+ARG MODE=release
+FROM alpine AS third
+ARG MODE=debug
+`,
+			want: map[string]string{
+				"MODE": "release",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := newTestDockerfile(t, tt.content).Args()
+
+			if len(args) != len(tt.want) {
+				t.Fatalf("Args() = %v, want %v", args, tt.want)
+			}
+
+			for name, want := range tt.want {
+				if args[name] != want {
+					t.Errorf("Args()[%q] = %q, want %q", name, args[name], want)
+				}
+			}
+		})
+	}
+}
@@ -3,29 +3,35 @@ package dockerfile
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"dagger.io/dockersdk/utils"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
 )
 
 // Dockerfile represents a parsed Dockerfile.
 type Dockerfile struct {
-	// filename is the name of the Dockerfile.
-	filename string
+	// path is the path of the Dockerfile relative to the codebase root.
+	path string
 	// content is the parsed result of the Dockerfile.
 	content  *parser.Result
 
 	// stages are the defined build stages in the Dockerfile.
 	stages  []string
-	// args are the build arguments in the Dockerfile.
+	// args are the build arguments in the Dockerfile with their effective
+	// default value.
 	args    map[string]string
-	// secrets are the secrets used in the Dockerfile.
-	secrets []string
+	// mounts are the secrets and SSH sockets mounted in the RUN
+	// instructions of the Dockerfile.
+	mounts *mounts
 }
 
 // NewDockerfile parses a Dockerfile from a given file and returns a Dockerfile
-// object with extracted stages, args, and secrets.
-func NewDockerfile(filename string, file *os.File) (*Dockerfile, error) {
+// object with extracted stages, args, and mounts.
+//
+// The path is the location of the Dockerfile relative to the codebase root.
+func NewDockerfile(path string, file *os.File) (*Dockerfile, error) {
 	content, err := parser.Parse(file)
 	if err != nil {
 		return nil, err
@@ -33,11 +39,15 @@ func NewDockerfile(filename string, file *os.File) (*Dockerfile, error) {
 
 	stages := []string{}
 	args := map[string]string{}
-	secrets := []string{}
+	mounts := &mounts{}
+
+	scope := newArgScope(content.EscapeToken)
 
 	for _, child := range content.AST.Children {
-		switch child.Value {
+		switch strings.ToUpper(child.Value) {
 		case "FROM":
+			scope.enterStage()
+
 			args := []string{}
 			for next := child.Next; next != nil; next = next.Next {
 				args = append(args, next.Value)
@@ -50,41 +60,71 @@ func NewDockerfile(filename string, file *os.File) (*Dockerfile, error) {
 
 			stages = append(stages, args[2])
 		case "ARG":
-			// Args does not handle self interpolation for simplicity.
-			// TODO: handle self interpolation (ARG XXX="XX-${XXXX}")
-			entry := strings.SplitN(child.Next.Value, "=", 2)
-			switch len(entry) {
-			case 1:
-				args[entry[0]] = ""
-			case 2:
-				args[entry[0]] = entry[1]
-			default:
-				panic(fmt.Errorf("invalid ARG: %s", child.Next.Value))
+			// A single ARG instruction may declare several arguments.
+			for next := child.Next; next != nil; next = next.Next {
+				name, value, err := scope.declare(next.Value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", child.StartLine, err)
+				}
+
+				// The same argument may be declared in several stages, we
+				// keep the first non empty value as default.
+				if current, exist := args[name]; !exist || current == "" {
+					args[name] = value
+				}
 			}
 		case "RUN":
-			// Parse RUN command to extract secrets if it exists
-			for _, flag := range child.Flags {
-				if !strings.Contains(flag, "--mount=type=secret,id=") {
-					continue
-				}
+			// Parse RUN command to extract its mounts if there are any
+			if len(child.Flags) == 0 {
+				continue
+			}
 
-				secrets = append(secrets, strings.TrimPrefix(flag, "--mount=type=secret,id="))
+			if err := mounts.add(child, scope); err != nil {
+				return nil, fmt.Errorf("line %d: %w", child.StartLine, err)
 			}
 		}
 	}
 
 	return &Dockerfile{
-		filename: filename,
+		path:     path,
 		content:  content,
 		stages:   stages,
 		args:     args,
-		secrets:  secrets,
+		mounts:   mounts,
 	}, nil
 }
 
+// Path returns the path of the Dockerfile relative to the codebase root.
+func (d *Dockerfile) Path() string {
+	return d.path
+}
+
 // Filename returns the filename of the Dockerfile.
 func (d *Dockerfile) Filename() string {
-	return d.filename
+	return filepath.Base(d.path)
+}
+
+// Dir returns the directory containing the Dockerfile, relative to the
+// codebase root.
+//
+// It's used as the build context of the Dockerfile.
+func (d *Dockerfile) Dir() string {
+	return filepath.Dir(d.path)
+}
+
+// Name returns an identifier of the Dockerfile derived from its directory
+// and its filename prefix.
+//
+// It's empty for the Dockerfile at the root of the codebase.
+// For example, "services/api/Dockerfile" is named "ServicesApi" and
+// "services/worker.Dockerfile" is named "ServicesWorker".
+func (d *Dockerfile) Name() string {
+	prefix := strings.TrimSuffix(d.Filename(), ".Dockerfile")
+	if prefix == d.Filename() {
+		return utils.FormatName(d.Dir())
+	}
+
+	return utils.FormatName(filepath.Join(d.Dir(), prefix))
 }
 
 // Stages returns build stages defined in the Dockerfile.
@@ -93,22 +133,36 @@ func (d *Dockerfile) Stages() []string {
 }
 
 // Args returns build arguments defined in the Dockerfile.
+//
+// Values are the effective defaults, after interpolation of the arguments
+// declared before them.
 func (d *Dockerfile) Args() map[string]string {
 	return d.args
 }
 
-// Secrets returns secrets defined in the Dockerfile.
-func (d *Dockerfile) Secrets() []string {
-	return d.secrets
+// Secrets returns secrets mounted in the Dockerfile.
+func (d *Dockerfile) Secrets() []*Secret {
+	return d.mounts.secrets
+}
+
+// SSH returns SSH agent sockets mounted in the Dockerfile.
+func (d *Dockerfile) SSH() []*SSH {
+	return d.mounts.ssh
 }
 
 // String displays the Dockerfile content.
 func (d *Dockerfile) String() string {
 	var result string
 
-	result += fmt.Sprintf("Filename: %s\n", d.filename)
+	result += fmt.Sprintf("Path: %s\n", d.path)
 	result += fmt.Sprintf("Stages: %s\n", strings.Join(d.Stages(), ", "))
-	result += fmt.Sprintf("Secrets: %s\n", strings.Join(d.Secrets(), ", "))
+	for _, secret := range d.Secrets() {
+		result += fmt.Sprintf("Secret: %s (required=%t)\n", secret.ID, secret.Required)
+	}
+
+	for _, ssh := range d.SSH() {
+		result += fmt.Sprintf("SSH: %s (required=%t)\n", ssh.ID, ssh.Required)
+	}
 
 	for key, value := range d.Args() {
 		result += fmt.Sprintf("ARG %s=%s\n", key, value)
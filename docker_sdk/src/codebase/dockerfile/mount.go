@@ -0,0 +1,129 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Secret represents a secret mounted in a RUN instruction with
+// `--mount=type=secret`.
+//
+// Only its ID is needed to pass it to the build, the build mounts it at its
+// `target` or exposes it as its `env` variable itself.
+type Secret struct {
+	// ID is the identifier of the secret.
+	ID string
+
+	// Required is true if the build must fail when the secret is missing.
+	Required bool
+}
+
+// SSH represents an SSH agent socket mounted in a RUN instruction with
+// `--mount=type=ssh`.
+type SSH struct {
+	// ID is the identifier of the SSH agent socket.
+	ID string
+
+	// Required is true if the build must fail when the socket is missing.
+	Required bool
+}
+
+// mounts holds the mounts declared in the RUN instructions of a Dockerfile.
+type mounts struct {
+	secrets []*Secret
+	ssh     []*SSH
+}
+
+// add parses the `--mount` flags of a RUN instruction and adds them to the
+// mounts.
+//
+// Mount options are expanded against the arguments in scope.
+// Cache mounts are run by the build itself, so they're skipped.
+// A secret or socket used by several instructions is only added once.
+func (m *mounts) add(node *parser.Node, scope *argScope) error {
+	instruction, err := instructions.ParseInstruction(node)
+	if err != nil {
+		return fmt.Errorf("failed to parse RUN instruction: %w", err)
+	}
+
+	run, ok := instruction.(*instructions.RunCommand)
+	if !ok {
+		return fmt.Errorf("expected RUN instruction, got %T", instruction)
+	}
+
+	err = run.Expand(func(word string) (string, error) {
+		value, _, err := scope.lex.ProcessWord(word, scope)
+
+		return value, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse mount: %w", err)
+	}
+
+	for _, mount := range instructions.GetMounts(run) {
+		switch mount.Type {
+		case instructions.MountTypeSecret:
+			m.addSecret(mount)
+		case instructions.MountTypeSSH:
+			m.addSSH(mount)
+		}
+	}
+
+	return nil
+}
+
+// addSecret adds a secret mount, resolving its ID the same way buildkit does:
+// from its source, its id or the base name of its target.
+func (m *mounts) addSecret(mount *instructions.Mount) {
+	id := mount.CacheID
+	if mount.Source != "" {
+		id = mount.Source
+	}
+
+	if id == "" {
+		if mount.Target == "" {
+			fmt.Printf("secret mount without id nor target, ignoring it\n")
+
+			return
+		}
+
+		id = path.Base(mount.Target)
+	}
+
+	secret := &Secret{
+		ID:       id,
+		Required: mount.Required,
+	}
+
+	for _, existing := range m.secrets {
+		if existing.ID == id {
+			// The secret is required if at least one instruction requires it.
+			existing.Required = existing.Required || secret.Required
+
+			return
+		}
+	}
+
+	m.secrets = append(m.secrets, secret)
+}
+
+// addSSH adds an SSH socket mount, its ID defaults to "default".
+func (m *mounts) addSSH(mount *instructions.Mount) {
+	id := mount.CacheID
+	if id == "" {
+		id = "default"
+	}
+
+	for _, existing := range m.ssh {
+		if existing.ID == id {
+			existing.Required = existing.Required || mount.Required
+
+			return
+		}
+	}
+
+	m.ssh = append(m.ssh, &SSH{ID: id, Required: mount.Required})
+}
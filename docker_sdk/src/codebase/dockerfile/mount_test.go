@@ -0,0 +1,106 @@
+package dockerfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDockerfileMounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantSecrets []*Secret
+		wantSSH     []*SSH
+	}{
+		{
+			name: "secret with id and default target",
+			content: `FROM alpine
+RUN --mount=type=secret,id=token cat /run/secrets/token
+`,
+			wantSecrets: []*Secret{{ID: "token"}},
+		},
+		{
+			name: "secret with target and required",
+			content: `FROM alpine
+RUN --mount=type=secret,id=foo,target=/x,required=true cat /x
+`,
+			wantSecrets: []*Secret{{ID: "foo", Required: true}},
+		},
+		{
+			name: "secret id from the target base name",
+			content: `FROM alpine
+RUN --mount=type=secret,target=/root/.npmrc npm ci
+`,
+			wantSecrets: []*Secret{{ID: ".npmrc"}},
+		},
+		{
+			name: "secret exposed as environment variable",
+			content: `FROM alpine
+RUN --mount=type=secret,id=token,env=TOKEN echo $TOKEN
+`,
+			wantSecrets: []*Secret{{ID: "token"}},
+		},
+		{
+			name: "secret options are expanded with arguments",
+			content: `FROM alpine
+ARG SECRET_ID=token
+RUN --mount=type=secret,id=${SECRET_ID} cat /run/secrets/token
+`,
+			wantSecrets: []*Secret{{ID: "token"}},
+		},
+		{
+			name: "secret used twice is required if one instruction requires it",
+			content: `FROM alpine
+RUN --mount=type=secret,id=token cat /run/secrets/token
+RUN --mount=type=secret,id=token,required=true cat /run/secrets/token
+`,
+			wantSecrets: []*Secret{{ID: "token", Required: true}},
+		},
+		{
+			name: "ssh sockets with default and custom ids",
+			content: `FROM alpine
+RUN --mount=type=ssh git clone git@github.com:foo/bar.git
+RUN --mount=type=ssh,id=github,required=true git clone git@github.com:foo/baz.git
+`,
+			wantSSH: []*SSH{{ID: "default"}, {ID: "github", Required: true}},
+		},
+		{
+			name: "cache mounts are skipped",
+			content: `FROM golang
+RUN --mount=type=cache,target=/root/.cache/go-build go build ./...
+`,
+		},
+		{
+			name: "several mounts in one instruction",
+			content: `FROM alpine
+RUN --mount=type=cache,target=/cache --mount=type=secret,id=token --mount=type=ssh make
+`,
+			wantSecrets: []*Secret{{ID: "token"}},
+			wantSSH:     []*SSH{{ID: "default"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile := newTestDockerfile(t, tt.content)
+
+			if !reflect.DeepEqual(dockerfile.Secrets(), tt.wantSecrets) {
+				t.Errorf("Secrets() = %+v, want %+v", formatMounts(dockerfile.Secrets()), formatMounts(tt.wantSecrets))
+			}
+
+			if !reflect.DeepEqual(dockerfile.SSH(), tt.wantSSH) {
+				t.Errorf("SSH() = %+v, want %+v", formatMounts(dockerfile.SSH()), formatMounts(tt.wantSSH))
+			}
+		})
+	}
+}
+
+// formatMounts formats the mounts values instead of their pointers.
+func formatMounts[T any](mounts []*T) []T {
+	values := []T{}
+	for _, mount := range mounts {
+		values = append(values, *mount)
+	}
+
+	return values
+}
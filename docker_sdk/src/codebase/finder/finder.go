@@ -2,51 +2,124 @@ package finder
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/moby/patternmatcher"
 )
 
 // Finder helps locate files.
 //
-// It operates within a specified root directory and its entries.
+// It operates within a specified root directory and all its sub directories.
 type Finder struct {
 	// dirPath specifies the root directory for searches and checks.
 	dirPath string
 
-	// dir holds the directory entries within dirPath.
-	dir []os.DirEntry
+	// files holds the paths of the files within dirPath, relative to it.
+	//
+	// Files matching one of the exclude patterns are not listed.
+	files []string
 }
 
-// New creates a Finder with a specified directory path and entries.
-func New(dirPath string, dir []os.DirEntry) *Finder {
+// New creates a Finder that walks the given directory path.
+//
+// Excludes follows the `.dockerignore` syntax, any file or directory matching
+// one of these patterns is skipped.
+func New(dirPath string, excludes []string) (*Finder, error) {
+	matcher, err := patternmatcher.New(excludes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse exclude patterns: %w", err)
+	}
+
+	files := []string{}
+	err = filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+
+		if relPath == "." {
+			return nil
+		}
+
+		excluded, err := matcher.MatchesOrParentMatches(relPath)
+		if err != nil {
+			return fmt.Errorf("failed to match %s: %w", relPath, err)
+		}
+
+		if excluded {
+			// A negated pattern may re-include a file inside an excluded
+			// directory so we can only skip it if there's none.
+			if entry.IsDir() && !matcher.Exclusions() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.IsDir() {
+			files = append(files, relPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", dirPath, err)
+	}
+
 	return &Finder{
 		dirPath: dirPath,
-		dir:     dir,
-	}
+		files:   files,
+	}, nil
 }
 
-// FindFileFromPattern searches for files matching any pattern.
+// FindFileFromPattern searches for files matching any pattern at the root of
+// the directory.
 //
 // Returns the full path of the first match and true, or an empty string
 // and false if no matches are found.
 func (f *Finder) FindFileFromPattern(patterns []string) (string, bool) {
-	for _, entry := range f.dir {
-		for _, pattern := range patterns {
-			matches, err := filepath.Match(pattern, entry.Name())
-			if err != nil {
-				fmt.Printf("failed to match pattern %s: %s\n",
-					pattern, err.Error())
-				continue
-			}
+	for _, file := range f.files {
+		if strings.ContainsRune(file, filepath.Separator) {
+			continue
+		}
 
-			if matches {
-				return f.dirPath + "/" + entry.Name(), true
-			}
+		if matchAny(patterns, file) {
+			return filepath.Join(f.dirPath, file), true
 		}
 	}
+
 	return "", false
 }
 
+// FindFilesFromPattern searches for files matching any pattern in the
+// directory and all its sub directories.
+//
+// Patterns are matched against the file's name only.
+// Returns the full path of every match, sorted in lexical order.
+func (f *Finder) FindFilesFromPattern(patterns []string) []string {
+	matches := []string{}
+
+	for _, file := range f.files {
+		if matchAny(patterns, filepath.Base(file)) {
+			matches = append(matches, filepath.Join(f.dirPath, file))
+		}
+	}
+
+	return matches
+}
+
+// RelativePath returns the given path relative to the Finder's root directory.
+func (f *Finder) RelativePath(path string) (string, error) {
+	return filepath.Rel(f.dirPath, path)
+}
+
 // IsPathDirectory checks if a given relative path is a directory.
 //
 // Returns true if it's a directory, or an error if the path can't be
@@ -60,4 +133,22 @@ func (f *Finder) IsPathDirectory(path string) (bool, error) {
 	}
 
 	return info.IsDir(), nil
-}
\ No newline at end of file
+}
+
+// matchAny returns true if the name matches one of the given patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		matches, err := filepath.Match(pattern, name)
+		if err != nil {
+			fmt.Printf("failed to match pattern %s: %s\n",
+				pattern, err.Error())
+			continue
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,486 @@
+// Package kubernetes converts compose services to Kubernetes manifests.
+//
+// Manifests only hold the subset of the Kubernetes API objects generated
+// from a compose project, their fields follow the API names so they're
+// marshaled as is.
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// nameLabel is the label selecting the pods of a compose service.
+	nameLabel = "app.kubernetes.io/name"
+
+	// dependsOnAnnotation lists the services a compose service depends on,
+	// since Kubernetes doesn't order the start of workloads.
+	dependsOnAnnotation = "docker-sdk.dagger.io/depends-on"
+
+	// volumesFilename is the name of the file holding the
+	// PersistentVolumeClaims of the named volumes, Kubernetes names can't
+	// contain dots so no service is written to it.
+	volumesFilename = "volumes.pvc.yaml"
+
+	// defaultVolumeSize is the storage requested for a named volume.
+	defaultVolumeSize = "1Gi"
+
+	// maxNameLength is the maximum length of a Kubernetes name, as a DNS
+	// label.
+	maxNameLength = 63
+)
+
+// invalidNameChars matches the characters not allowed in Kubernetes names.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// FromCompose converts the given services of the compose project to
+// Kubernetes manifests, returned by file name, with a message for each
+// property that cannot be converted as is.
+//
+// Each service is written to `<service>.yaml` with a Deployment, or a Job
+// if it's a one-shot service, and a Service exposing its ports. The
+// PersistentVolumeClaims of the named volumes are written to
+// `volumes.pvc.yaml`.
+// Environment variables without value reference the `<service>-env` Secret,
+// which must be created separately. Secrets and configs are mounted from the
+// Secret and the ConfigMap named after them, which must also be created
+// separately with their content under the key of the same name.
+//
+// Returns an error if services or named volumes with different names end
+// up with the same Kubernetes name (e.g., `web-api` and `web_api`).
+func FromCompose(compose *dockercompose.DockerCompose, services []*dockercompose.Service) (map[string]string, []string, error) {
+	files := map[string]string{}
+	namedVolumes := map[string]bool{}
+	names := map[string]string{}
+	warnings := []string{}
+
+	for _, service := range services {
+		if err := checkName(names, "service", service.Name()); err != nil {
+			return nil, nil, err
+		}
+
+		warnings = append(warnings, serviceWarnings(service)...)
+
+		manifests := []interface{}{}
+
+		labels := map[string]string{nameLabel: Name(service.Name())}
+		metadata := Metadata{
+			Name:        Name(service.Name()),
+			Labels:      labels,
+			Annotations: annotations(service),
+		}
+
+		pod, volumes := podSpec(service)
+		for _, volume := range volumes {
+			namedVolumes[volume] = true
+		}
+
+		template := PodTemplate{
+			Metadata: Metadata{Labels: labels},
+			Spec:     pod,
+		}
+
+		if compose.IsOneShot(service.Name()) {
+			template.Spec.RestartPolicy = "Never"
+
+			manifests = append(manifests, &Job{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Metadata:   metadata,
+				Spec:       JobSpec{Template: template},
+			})
+		} else {
+			manifests = append(manifests, &Deployment{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Metadata:   metadata,
+				Spec: DeploymentSpec{
+					Replicas: 1,
+					Selector: Selector{MatchLabels: labels},
+					Template: template,
+				},
+			})
+		}
+
+		if ports := servicePorts(service); len(ports) != 0 {
+			manifests = append(manifests, &Service{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Metadata:   Metadata{Name: Name(service.Name()), Labels: labels},
+				Spec: ServiceSpec{
+					Selector: labels,
+					Ports:    ports,
+				},
+			})
+		}
+
+		content, err := marshal(manifests)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert service %s: %w", service.Name(), err)
+		}
+
+		files[fmt.Sprintf("%s.yaml", Name(service.Name()))] = content
+	}
+
+	if len(namedVolumes) != 0 {
+		claims, err := persistentVolumeClaims(namedVolumes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		content, err := marshal(claims)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert volumes: %w", err)
+		}
+
+		files[volumesFilename] = content
+	}
+
+	return files, warnings, nil
+}
+
+// checkName records the Kubernetes name of the given compose name in names.
+//
+// Returns an error if the name is not a valid Kubernetes name once
+// converted or if another name of the same kind is converted to it.
+func checkName(names map[string]string, kind string, name string) error {
+	converted := Name(name)
+	if converted == "" {
+		return fmt.Errorf("%s %s cannot be converted to a Kubernetes name, rename it", kind, name)
+	}
+
+	if existing, ok := names[converted]; ok && existing != name {
+		return fmt.Errorf("%ss %s and %s are both converted to the %s Kubernetes name, rename one of them", kind, existing, name, converted)
+	}
+
+	names[converted] = name
+
+	return nil
+}
+
+// serviceWarnings returns a message for each property of the service that
+// cannot be converted as is.
+func serviceWarnings(service *dockercompose.Service) []string {
+	warnings := []string{}
+
+	volumes, _ := service.Volumes()
+	for _, volume := range volumes {
+		warnings = append(warnings, fmt.Sprintf("bind mount %s of service %s cannot be converted to Kubernetes, mounting an empty directory instead", volume.Origin(), service.Name()))
+	}
+
+	if service.Source().Type != dockercompose.SourceTypeImage {
+		warnings = append(warnings, fmt.Sprintf("service %s is built from a Dockerfile, using the image %s", service.Name(), Name(service.Name())))
+	}
+
+	for _, secret := range service.MountedSecrets() {
+		warnings = append(warnings, fmt.Sprintf("secret %s of service %s is mounted from the %s Secret, which must be created separately", secret.Name(), service.Name(), Name(secret.Name())))
+	}
+
+	for _, config := range service.Configs() {
+		warnings = append(warnings, fmt.Sprintf("config %s of service %s is mounted from the %s ConfigMap, which must be created separately", config.Name(), service.Name(), Name(config.Name())))
+	}
+
+	for _, warning := range service.Warnings() {
+		warnings = append(warnings, fmt.Sprintf("service %s: %s", service.Name(), warning))
+	}
+
+	return warnings
+}
+
+// Name returns a valid Kubernetes name from the given compose name, e.g.,
+// `my_service` becomes `my-service`, truncated to 63 characters.
+func Name(name string) string {
+	return truncate(strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-"), maxNameLength)
+}
+
+// truncate returns the Kubernetes name cut to the given length, without
+// trailing dash.
+func truncate(name string, length int) string {
+	if len(name) > length {
+		name = name[:length]
+	}
+
+	return strings.TrimRight(name, "-")
+}
+
+// uniqueName returns the given Kubernetes name, suffixed by an index if it's
+// already used, and records it in used.
+func uniqueName(used map[string]bool, name string) string {
+	unique := truncate(name, maxNameLength)
+	for i := 2; used[unique]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		unique = truncate(name, maxNameLength-len(suffix)) + suffix
+	}
+
+	used[unique] = true
+
+	return unique
+}
+
+// SecretName returns the name of the Secret holding the environment
+// variables without value of the given service.
+func SecretName(service string) string {
+	return fmt.Sprintf("%s-env", Name(service))
+}
+
+// annotations returns the labels of the service, as annotations since their
+// values are free text, and the services it depends on.
+func annotations(service *dockercompose.Service) map[string]string {
+	annotations := map[string]string{}
+	for key, value := range service.Labels() {
+		annotations[key] = value
+	}
+
+	dependencies := []string{}
+	for _, dependency := range service.Dependencies() {
+		dependencies = append(dependencies, Name(dependency.Name()))
+	}
+
+	if len(dependencies) != 0 {
+		annotations[dependsOnAnnotation] = strings.Join(dependencies, ",")
+	}
+
+	return annotations
+}
+
+// podSpec returns the pod running the service and the names of the named
+// volumes it mounts.
+func podSpec(service *dockercompose.Service) (PodSpec, []string) {
+	container := Container{
+		Name:       Name(service.Name()),
+		Image:      image(service),
+		WorkingDir: service.Workdir(),
+		Env:        env(service),
+	}
+
+	if entrypoint, ok := service.Entrypoint(); ok {
+		container.Command = entrypoint
+	}
+
+	if command, ok := service.Command(); ok {
+		container.Args = command
+	}
+
+	for _, port := range service.Ports() {
+		container.Ports = append(container.Ports, ContainerPort{
+			ContainerPort: port.Target(),
+			Protocol:      strings.ToUpper(string(port.Protocol())),
+		})
+	}
+
+	if healthcheck := service.Healthcheck(); healthcheck != nil {
+		container.ReadinessProbe = &Probe{
+			Exec:                ExecAction{Command: healthcheck.Test()},
+			InitialDelaySeconds: seconds(healthcheck.StartPeriod()),
+			PeriodSeconds:       seconds(healthcheck.Interval()),
+			TimeoutSeconds:      seconds(healthcheck.Timeout()),
+			FailureThreshold:    healthcheck.Retries(),
+		}
+	}
+
+	pod := PodSpec{}
+	namedVolumes := []string{}
+
+	// Volumes are identified by their source, so a named volume, a secret or
+	// a config mounted several times is only declared once. Different
+	// sources converted to the same name (e.g., the `/a_b` and `/a-b` bind
+	// mounts) get a name suffixed by an index.
+	mounted := map[string]string{}
+	used := map[string]bool{}
+
+	mount := func(source string, volume Volume, target string, subPath string) {
+		name, ok := mounted[source]
+		if !ok {
+			name = uniqueName(used, volume.Name)
+			mounted[source] = name
+
+			volume.Name = name
+			pod.Volumes = append(pod.Volumes, volume)
+		}
+
+		container.VolumeMounts = append(container.VolumeMounts, VolumeMount{Name: name, MountPath: target, SubPath: subPath})
+	}
+
+	volumes, caches := service.Volumes()
+	for _, volume := range volumes {
+		mount(fmt.Sprintf("bind:%s", volume.Target()), Volume{
+			Name:     fmt.Sprintf("bind-%s", Name(volume.Target())),
+			EmptyDir: &EmptyDirVolumeSource{},
+		}, volume.Target(), "")
+	}
+
+	for _, cache := range caches {
+		if !cache.Named() {
+			mount(fmt.Sprintf("cache:%s", cache.Path()), Volume{
+				Name:     fmt.Sprintf("cache-%s", Name(cache.Path())),
+				EmptyDir: &EmptyDirVolumeSource{},
+			}, cache.Path(), "")
+
+			continue
+		}
+
+		namedVolumes = append(namedVolumes, cache.Name())
+
+		mount(fmt.Sprintf("volume:%s", cache.Name()), Volume{
+			Name:                  Name(cache.Name()),
+			PersistentVolumeClaim: &PersistentVolumeClaimVolumeSource{ClaimName: Name(cache.Name())},
+		}, cache.Path(), "")
+	}
+
+	for _, tmpfs := range service.Tmpfs() {
+		source := &EmptyDirVolumeSource{Medium: "Memory"}
+		if tmpfs.Size() != 0 {
+			source.SizeLimit = fmt.Sprintf("%d", tmpfs.Size())
+		}
+
+		mount(fmt.Sprintf("tmpfs:%s", tmpfs.Path()), Volume{
+			Name:     fmt.Sprintf("tmpfs-%s", Name(tmpfs.Path())),
+			EmptyDir: source,
+		}, tmpfs.Path(), "")
+	}
+
+	// Secrets and configs are single files, their key is mounted at their
+	// target rather than the whole volume.
+	for _, secret := range service.MountedSecrets() {
+		mount(fmt.Sprintf("secret:%s", secret.Name()), Volume{
+			Name:   fmt.Sprintf("secret-%s", Name(secret.Name())),
+			Secret: &SecretVolumeSource{SecretName: Name(secret.Name())},
+		}, secret.Target(), Name(secret.Name()))
+	}
+
+	for _, config := range service.Configs() {
+		mount(fmt.Sprintf("config:%s", config.Name()), Volume{
+			Name:      fmt.Sprintf("config-%s", Name(config.Name())),
+			ConfigMap: &ConfigMapVolumeSource{Name: Name(config.Name())},
+		}, config.Target(), Name(config.Name()))
+	}
+
+	pod.Containers = []Container{container}
+
+	return pod, namedVolumes
+}
+
+// image returns the image of the service, services built from a Dockerfile
+// use an image named after them that must be built and pushed separately.
+func image(service *dockercompose.Service) string {
+	source := service.Source()
+	if source.Type == dockercompose.SourceTypeImage {
+		return source.Image.Ref
+	}
+
+	return Name(service.Name())
+}
+
+// env returns the environment variables of the service sorted by name,
+// variables without value reference the service's Secret.
+func env(service *dockercompose.Service) []EnvVar {
+	vars := []EnvVar{}
+
+	values, secrets := service.Environment()
+	for name, value := range values {
+		vars = append(vars, EnvVar{Name: name, Value: *value})
+	}
+
+	for _, name := range secrets {
+		vars = append(vars, EnvVar{
+			Name: name,
+			ValueFrom: &EnvVarSource{
+				SecretKeyRef: SecretKeySelector{Name: SecretName(service.Name()), Key: name},
+			},
+		})
+	}
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+
+	return vars
+}
+
+// servicePorts returns the ports of the Service exposing the service, on the
+// same numbers as its container so other services reach it like in compose.
+func servicePorts(service *dockercompose.Service) []ServicePort {
+	ports := []ServicePort{}
+
+	for _, port := range service.Ports() {
+		ports = append(ports, ServicePort{
+			Name:       fmt.Sprintf("%s-%d", port.Protocol(), port.Target()),
+			Port:       port.Target(),
+			TargetPort: port.Target(),
+			Protocol:   strings.ToUpper(string(port.Protocol())),
+		})
+	}
+
+	return ports
+}
+
+// persistentVolumeClaims returns a PersistentVolumeClaim per named volume,
+// sorted by name.
+//
+// Returns an error if named volumes end up with the same Kubernetes name.
+func persistentVolumeClaims(namedVolumes map[string]bool) ([]interface{}, error) {
+	names := []string{}
+	for name := range namedVolumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	claimNames := map[string]string{}
+	claims := []interface{}{}
+	for _, name := range names {
+		if err := checkName(claimNames, "volume", name); err != nil {
+			return nil, err
+		}
+
+		claims = append(claims, &PersistentVolumeClaim{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Metadata:   Metadata{Name: Name(name)},
+			Spec: PersistentVolumeClaimSpec{
+				AccessModes: []string{"ReadWriteOnce"},
+				Resources: ResourceRequirements{
+					Requests: map[string]string{"storage": defaultVolumeSize},
+				},
+			},
+		})
+	}
+
+	return claims, nil
+}
+
+// seconds returns the duration in seconds, at least 1 if it's not zero.
+func seconds(duration time.Duration) int {
+	if duration == 0 {
+		return 0
+	}
+
+	return max(int(duration.Seconds()), 1)
+}
+
+// marshal returns the manifests as a multi-document YAML file.
+func marshal(manifests []interface{}) (string, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	for _, manifest := range manifests {
+		if err := encoder.Encode(manifest); err != nil {
+			return "", err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,271 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/codebase/finder"
+	"github.com/compose-spec/compose-go/types"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "api", want: "api"},
+		{name: "web_app", want: "web-app"},
+		{name: "Web.App", want: "web-app"},
+		{name: "_private_", want: "private"},
+		{name: "a__b", want: "a-b"},
+		{name: "___", want: ""},
+		{name: strings.Repeat("a", 70), want: strings.Repeat("a", 63)},
+		{name: strings.Repeat("a", 62) + "_b", want: strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Name(tt.name); got != tt.want {
+				t.Errorf("Name(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := map[string]bool{}
+	long := strings.Repeat("a", 70)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "data", want: "data"},
+		{name: "data", want: "data-2"},
+		{name: "data", want: "data-3"},
+		{name: long, want: strings.Repeat("a", 63)},
+		{name: long, want: strings.Repeat("a", 61) + "-2"},
+	}
+
+	for _, tt := range tests {
+		if got := uniqueName(used, tt.name); got != tt.want {
+			t.Errorf("uniqueName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFromCompose(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantFiles    []string
+		wantContains map[string][]string
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "deployment, job and volumes",
+			content: `
+services:
+  web_app:
+    image: nginx
+    ports:
+      - "8080:80"
+    volumes:
+      - data:/data
+    depends_on:
+      migrations:
+        condition: service_completed_successfully
+  migrations:
+    image: alpine
+volumes:
+  data:
+`,
+			wantFiles: []string{"migrations.yaml", "volumes.pvc.yaml", "web-app.yaml"},
+			wantContains: map[string][]string{
+				"web-app.yaml": {
+					"kind: Deployment",
+					"kind: Service",
+					"docker-sdk.dagger.io/depends-on: migrations",
+					"claimName: data",
+				},
+				"migrations.yaml":  {"kind: Job", "restartPolicy: Never"},
+				"volumes.pvc.yaml": {"kind: PersistentVolumeClaim", "name: data"},
+			},
+			wantWarnings: []string{},
+		},
+		{
+			name: "service named volumes",
+			content: `
+services:
+  volumes:
+    image: alpine
+    volumes:
+      - data:/data
+volumes:
+  data:
+`,
+			wantFiles:    []string{"volumes.pvc.yaml", "volumes.yaml"},
+			wantWarnings: []string{},
+		},
+		{
+			name: "warnings",
+			content: `
+services:
+  api:
+    build: .
+    volumes:
+      - ./src:/src
+`,
+			wantFiles: []string{"api.yaml"},
+			wantWarnings: []string{
+				"bind mount ./src of service api cannot be converted to Kubernetes, mounting an empty directory instead",
+				"service api is built from a Dockerfile, using the image api",
+			},
+		},
+		{
+			name: "secrets, configs and compose warnings",
+			content: `
+services:
+  api:
+    image: alpine
+    read_only: true
+    secrets:
+      - token
+    configs:
+      - source: app_config
+        target: /etc/app.conf
+secrets:
+  token:
+    environment: TOKEN
+configs:
+  app_config:
+    content: debug
+`,
+			wantFiles: []string{"api.yaml"},
+			wantContains: map[string][]string{
+				"api.yaml": {
+					"secretName: token",
+					"mountPath: /run/secrets/token",
+					"subPath: token",
+					"name: app-config",
+					"mountPath: /etc/app.conf",
+					"subPath: app-config",
+				},
+			},
+			wantWarnings: []string{
+				"secret token of service api is mounted from the token Secret, which must be created separately",
+				"config app_config of service api is mounted from the app-config ConfigMap, which must be created separately",
+				"service api: read_only is not supported by Dagger, ignoring it",
+			},
+		},
+		{
+			name: "volumes with the same pod volume name",
+			content: `
+services:
+  api:
+    image: alpine
+    tmpfs:
+      - /a_b
+      - /a-b
+`,
+			wantFiles: []string{"api.yaml"},
+			wantContains: map[string][]string{
+				"api.yaml": {
+					"name: tmpfs-a-b\n",
+					"name: tmpfs-a-b-2\n",
+				},
+			},
+			wantWarnings: []string{},
+		},
+		{
+			name: "services with the same name",
+			content: `
+services:
+  web-api:
+    image: alpine
+  web_api:
+    image: alpine
+`,
+			wantErr: "services web-api and web_api are both converted to the web-api Kubernetes name",
+		},
+		{
+			name: "volumes with the same name",
+			content: `
+services:
+  api:
+    image: alpine
+    volumes:
+      - data-1:/a
+      - data_1:/b
+volumes:
+  data-1:
+  data_1:
+`,
+			wantErr: "volumes data-1 and data_1 are both converted to the data-1 Kubernetes name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			// Bind mounts are only mounted from existing paths.
+			if err := os.Mkdir(filepath.Join(dir, "src"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			finder, err := finder.New(dir, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			compose, err := dockercompose.NewDockerCompose(context.Background(), dir, []types.ConfigFile{
+				{Filename: "docker-compose.yml", Content: []byte(tt.content)},
+			}, finder)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, warnings, err := FromCompose(compose, compose.Services())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FromCompose() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filenames := []string{}
+			for filename := range files {
+				filenames = append(filenames, filename)
+			}
+			slices.Sort(filenames)
+
+			if !slices.Equal(filenames, tt.wantFiles) {
+				t.Errorf("FromCompose() files = %v, want %v", filenames, tt.wantFiles)
+			}
+
+			for filename, wantContains := range tt.wantContains {
+				for _, want := range wantContains {
+					if !strings.Contains(files[filename], want) {
+						t.Errorf("%s doesn't contain %q:\n%s", filename, want, files[filename])
+					}
+				}
+			}
+
+			if !slices.Equal(warnings, tt.wantWarnings) {
+				t.Errorf("FromCompose() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
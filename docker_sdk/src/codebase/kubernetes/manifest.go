@@ -0,0 +1,187 @@
+package kubernetes
+
+// Metadata is the metadata of a Kubernetes object.
+type Metadata struct {
+	Name        string            `yaml:"name,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Deployment runs the long-running compose services.
+type Deployment struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   Metadata       `yaml:"metadata"`
+	Spec       DeploymentSpec `yaml:"spec"`
+}
+
+// DeploymentSpec is the specification of a Deployment.
+type DeploymentSpec struct {
+	Replicas int         `yaml:"replicas"`
+	Selector Selector    `yaml:"selector"`
+	Template PodTemplate `yaml:"template"`
+}
+
+// Job runs the one-shot compose services, the ones others wait for with the
+// `service_completed_successfully` condition.
+type Job struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       JobSpec  `yaml:"spec"`
+}
+
+// JobSpec is the specification of a Job.
+type JobSpec struct {
+	BackoffLimit int         `yaml:"backoffLimit"`
+	Template     PodTemplate `yaml:"template"`
+}
+
+// Selector selects the pods of a Deployment.
+type Selector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+// PodTemplate is the template of the pods of a Deployment or a Job.
+type PodTemplate struct {
+	Metadata Metadata `yaml:"metadata"`
+	Spec     PodSpec  `yaml:"spec"`
+}
+
+// PodSpec is the specification of a pod.
+type PodSpec struct {
+	RestartPolicy string      `yaml:"restartPolicy,omitempty"`
+	Containers    []Container `yaml:"containers"`
+	Volumes       []Volume    `yaml:"volumes,omitempty"`
+}
+
+// Container is a container of a pod.
+type Container struct {
+	Name           string          `yaml:"name"`
+	Image          string          `yaml:"image"`
+	Command        []string        `yaml:"command,omitempty"`
+	Args           []string        `yaml:"args,omitempty"`
+	WorkingDir     string          `yaml:"workingDir,omitempty"`
+	Env            []EnvVar        `yaml:"env,omitempty"`
+	Ports          []ContainerPort `yaml:"ports,omitempty"`
+	VolumeMounts   []VolumeMount   `yaml:"volumeMounts,omitempty"`
+	ReadinessProbe *Probe          `yaml:"readinessProbe,omitempty"`
+}
+
+// EnvVar is an environment variable of a container, either a value or a
+// reference to a Secret key.
+type EnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
+// EnvVarSource is the source of an environment variable's value.
+type EnvVarSource struct {
+	SecretKeyRef SecretKeySelector `yaml:"secretKeyRef"`
+}
+
+// SecretKeySelector references a key of a Secret.
+type SecretKeySelector struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+// ContainerPort is a port a container listens on.
+type ContainerPort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol"`
+}
+
+// VolumeMount mounts a volume of the pod in a container.
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+}
+
+// Volume is a volume of a pod, backed by a PersistentVolumeClaim, an empty
+// directory, a Secret or a ConfigMap.
+type Volume struct {
+	Name                  string                             `yaml:"name"`
+	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `yaml:"persistentVolumeClaim,omitempty"`
+	EmptyDir              *EmptyDirVolumeSource              `yaml:"emptyDir,omitempty"`
+	Secret                *SecretVolumeSource                `yaml:"secret,omitempty"`
+	ConfigMap             *ConfigMapVolumeSource             `yaml:"configMap,omitempty"`
+}
+
+// PersistentVolumeClaimVolumeSource references a PersistentVolumeClaim.
+type PersistentVolumeClaimVolumeSource struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+// EmptyDirVolumeSource is an empty directory, in memory for tmpfs.
+type EmptyDirVolumeSource struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+// SecretVolumeSource mounts the keys of a Secret as files.
+type SecretVolumeSource struct {
+	SecretName string `yaml:"secretName"`
+}
+
+// ConfigMapVolumeSource mounts the keys of a ConfigMap as files.
+type ConfigMapVolumeSource struct {
+	Name string `yaml:"name"`
+}
+
+// Probe checks a container with a command.
+type Probe struct {
+	Exec                ExecAction `yaml:"exec"`
+	InitialDelaySeconds int        `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int        `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int        `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int        `yaml:"failureThreshold,omitempty"`
+}
+
+// ExecAction is a command run in a container.
+type ExecAction struct {
+	Command []string `yaml:"command"`
+}
+
+// Service exposes the ports of a compose service to the other services.
+type Service struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   Metadata    `yaml:"metadata"`
+	Spec       ServiceSpec `yaml:"spec"`
+}
+
+// ServiceSpec is the specification of a Service.
+type ServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []ServicePort     `yaml:"ports"`
+}
+
+// ServicePort is a port of a Service.
+type ServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol"`
+}
+
+// PersistentVolumeClaim claims the storage of a named volume.
+type PersistentVolumeClaim struct {
+	APIVersion string                    `yaml:"apiVersion"`
+	Kind       string                    `yaml:"kind"`
+	Metadata   Metadata                  `yaml:"metadata"`
+	Spec       PersistentVolumeClaimSpec `yaml:"spec"`
+}
+
+// PersistentVolumeClaimSpec is the specification of a PersistentVolumeClaim.
+type PersistentVolumeClaimSpec struct {
+	AccessModes []string             `yaml:"accessModes"`
+	Resources   ResourceRequirements `yaml:"resources"`
+}
+
+// ResourceRequirements are the resources requested by a claim.
+type ResourceRequirements struct {
+	Requests map[string]string `yaml:"requests"`
+}
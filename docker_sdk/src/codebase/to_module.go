@@ -8,18 +8,24 @@ import (
 // Converts a Codebase instance to a Dagger Docker module.
 //
 // Initializes a new Docker module with the given name and optionally configures
-// it with a Dockerfile and a Docker Compose file if they are present in the
-// Codebase instance.
-func (c *Codebase) ToModule(name string) *module.Module {
+// it with the Dockerfiles, a Docker Compose file and a bake file if they are
+// present in the Codebase instance.
+//
+// Returns an error if the functions of a file cannot be exposed.
+func (c *Codebase) ToModule(name string) (*module.Module, error) {
 	dockerModule := docker.New("Docker")
 
-	if c.dockerfile != nil {
-		dockerModule = dockerModule.WithDockerfile(c.dockerfile)
+	for _, dockerfile := range c.dockerfiles {
+		dockerModule = dockerModule.WithDockerfile(dockerfile)
 	}
 
 	if c.dockercompose != nil {
 		dockerModule = dockerModule.WithDockerCompose(c.dockercompose)
 	}
 
+	if c.bake != nil {
+		dockerModule = dockerModule.WithBake(c.bake)
+	}
+
 	return module.Build(name, dockerModule)
 }
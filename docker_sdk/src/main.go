@@ -30,7 +30,14 @@ func main() {
 		os.Exit(2)
 	}
 
-	if err := codebase.ToModule(formattedName).Dispatch(ctx); err != nil {
+	module, err := codebase.ToModule(formattedName)
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to get user's codebase: %w", err))
+
+		os.Exit(2)
+	}
+
+	if err := module.Dispatch(ctx); err != nil {
 		os.Exit(2)
 	}
 }
@@ -0,0 +1,183 @@
+package bake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/bake"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// Bake builds the targets and groups of a Buildx bake file.
+type Bake struct {
+	// Dir is the directory containing the bake file.
+	Dir *dagger.Directory
+
+	// bake is the parsed bake file.
+	bake *bake.Bake
+
+	// funcMap maps function names to the function building the target or
+	// group.
+	funcMap map[string]object.Function
+}
+
+// New creates a new Bake instance with the given directory and bake file.
+//
+// A function is registered for each target and group, a group named after a
+// target is suffixed by "Group".
+// Matrix targets also get a function named after their block that builds all
+// the combinations.
+//
+// Returns an error if targets or groups with different names end up with the
+// same function name (e.g., `app-dev` and `app_dev`), or variables with the
+// same argument name (e.g., `FOO_BAR` and `foo_bar`).
+func New(dir *dagger.Directory, bakeFile *bake.Bake) (*Bake, error) {
+	b := &Bake{
+		Dir:     dir,
+		bake:    bakeFile,
+		funcMap: make(map[string]object.Function),
+	}
+
+	if err := checkVariables(bakeFile.Variables()); err != nil {
+		return nil, err
+	}
+
+	for _, target := range bakeFile.Targets() {
+		fct := &targetFunc{b: b, name: target.Name(), fnName: utils.FormatName(target.Name())}
+		if err := b.register(fct); err != nil {
+			return nil, err
+		}
+
+		// The block function is shared by the combinations of the matrix.
+		blockFnName := utils.FormatName(target.BlockName())
+		if existing, ok := b.funcMap[blockFnName].(*targetFunc); ok && existing.name == target.BlockName() {
+			continue
+		}
+
+		if err := b.register(&targetFunc{b: b, name: target.BlockName(), fnName: blockFnName}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, group := range bakeFile.Groups() {
+		fct := &targetFunc{b: b, name: group.Name(), fnName: utils.FormatName(group.Name()), group: true}
+		if existing, ok := b.funcMap[fct.fnName].(*targetFunc); ok && !existing.group && existing.name == group.Name() {
+			fct.fnName += "Group"
+		}
+
+		if err := b.register(fct); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// register adds the function of a target or group.
+//
+// Returns an error if another target or group is registered under the same
+// function name.
+func (b *Bake) register(fct *targetFunc) error {
+	if existing, ok := b.funcMap[fct.fnName].(*targetFunc); ok {
+		return fmt.Errorf("%s and %s are both exposed as the %s function, rename one of them", existing.name, fct.name, fct.fnName)
+	}
+
+	b.funcMap[fct.fnName] = fct
+
+	return nil
+}
+
+// checkVariables returns an error if variables with different names are
+// exposed as the same argument.
+//
+// Dagger formats argument names, so they're compared case-insensitively.
+func checkVariables(variables []*bake.Variable) error {
+	argNames := map[string]string{}
+	for _, variable := range variables {
+		argName := strings.ToLower(utils.FormatEnvVariableName(variable.Name()))
+		if existing, ok := argNames[argName]; ok {
+			return fmt.Errorf("variables %s and %s are both exposed as the %s argument, rename one of them", existing, variable.Name(), utils.FormatEnvVariableName(variable.Name()))
+		}
+
+		argNames[argName] = variable.Name()
+	}
+
+	return nil
+}
+
+// Name returns the name of the object: "Bake".
+func (b *Bake) Name() string {
+	return "Bake"
+}
+
+// Description provides a brief description of Bake.
+func (b *Bake) Description() string {
+	return fmt.Sprintf("Build targets of %s", b.bake.Filename())
+}
+
+// New creates a new Bake object instance with optional directory input.
+func (b *Bake) New(input object.InputArgs) object.Object {
+	var dir *dagger.Directory
+
+	if input["dir"] != nil {
+		dir = utils.LoadDirectoryFromID([]byte(input["dir"]))
+	}
+
+	return &Bake{
+		Dir:  dir,
+		bake: b.bake,
+	}
+}
+
+// AddTypeDef adds the module type definition for this object with all
+// its functions.
+func (b *Bake) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().WithObject(b.Name())
+
+		for _, fct := range b.funcMap {
+			mod, object = fct.AddTypeDefToObject(ctx, mod, object)
+		}
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new Bake object from a saved state.
+func (b *Bake) Load(state object.State) (object.Object, error) {
+	return b.load(state)
+}
+
+// load reconstructs a new Bake from state data.
+func (b *Bake) load(state object.State) (*Bake, error) {
+	parentMap := make(map[string]interface{})
+	err := json.Unmarshal(state, &parentMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	cpyBake := &Bake{
+		bake:    b.bake,
+		funcMap: b.funcMap,
+	}
+
+	if parentMap["Dir"] != nil {
+		cpyBake.Dir = dag.LoadDirectoryFromID(dagger.DirectoryID(parentMap["Dir"].(string)))
+	}
+
+	return cpyBake, nil
+}
+
+// Invoke executes a function associated from its name with its object's state and input.
+func (b *Bake) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	if b.funcMap[fnName] == nil {
+		return nil, fmt.Errorf("unknown function %s", fnName)
+	}
+
+	return b.funcMap[fnName].Invoke(ctx, state, input)
+}
@@ -0,0 +1,106 @@
+package bake
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"dagger.io/dockersdk/codebase/bake"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantFuncs []string
+		wantErr   string
+	}{
+		{
+			name: "targets, matrix blocks and groups",
+			content: `
+target "api" {}
+target "web" {
+  name   = "web-${mode}"
+  matrix = {
+    mode = ["dev", "prod"]
+  }
+}
+group "default" {
+  targets = ["api", "web"]
+}
+group "api" {
+  targets = ["api"]
+}
+`,
+			wantFuncs: []string{"Api", "ApiGroup", "Default", "Web", "WebDev", "WebProd"},
+		},
+		{
+			name: "targets with the same function name",
+			content: `
+target "app-dev" {}
+target "app_dev" {}
+`,
+			wantErr: "app-dev and app_dev are both exposed as the AppDev function",
+		},
+		{
+			name: "group and target with the same function name",
+			content: `
+target "app-dev" {}
+group "app_dev" {
+  targets = ["app-dev"]
+}
+`,
+			wantErr: "app-dev and app_dev are both exposed as the AppDev function",
+		},
+		{
+			name: "variables with the same argument name",
+			content: `
+variable "FOO_BAR" {}
+variable "foo_bar" {}
+target "api" {}
+`,
+			wantErr: "variables FOO_BAR and foo_bar are both exposed as the FooBar argument",
+		},
+		{
+			name: "variables with the same argument name in another case",
+			content: `
+variable "FOOBAR" {}
+variable "fooBar" {}
+target "api" {}
+`,
+			wantErr: "variables FOOBAR and fooBar are both exposed as the Foobar argument",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bakeFile, err := bake.NewBake("docker-bake.hcl", []byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := New(nil, bakeFile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			funcs := []string{}
+			for name := range b.funcMap {
+				funcs = append(funcs, name)
+			}
+			slices.Sort(funcs)
+
+			if !slices.Equal(funcs, tt.wantFuncs) {
+				t.Errorf("New() functions = %v, want %v", funcs, tt.wantFuncs)
+			}
+		})
+	}
+}
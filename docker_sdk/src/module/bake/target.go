@@ -0,0 +1,205 @@
+package bake
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/bake"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// targetFunc builds a bake target or group.
+type targetFunc struct {
+	// b refers to the Dagger Object Bake instance.
+	b *Bake
+
+	// name is the name of the target or group in the bake file.
+	name string
+
+	// fnName is the name of the function.
+	fnName string
+
+	// group is true if the function builds a group.
+	group bool
+}
+
+// targets returns the bake targets built by the function.
+func (t *targetFunc) targets() ([]*bake.Target, error) {
+	return t.b.bake.ResolveTargets(t.name)
+}
+
+// variables returns the bake variables used by the built targets, without
+// duplicates.
+func (t *targetFunc) variables() []*bake.Variable {
+	targets, err := t.targets()
+	if err != nil {
+		return nil
+	}
+
+	variables := []*bake.Variable{}
+	for _, target := range targets {
+		variables = append(variables, target.Variables()...)
+	}
+
+	return utils.RemoveListDuplicates(variables)
+}
+
+// build builds a container for each platform of the target.
+//
+// The build context is filtered by its `.dockerignore` file before building.
+// The target's tags and outputs are ignored.
+func (t *targetFunc) build(ctx context.Context, target *bake.Target, values map[string]cty.Value) ([]*dagger.Container, error) {
+	config, err := target.Config(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Context == "" {
+		config.Context = "."
+	}
+
+	if config.Dockerfile == "" {
+		config.Dockerfile = "Dockerfile"
+	}
+
+	opts := dagger.DirectoryDockerBuildOpts{
+		Dockerfile: config.Dockerfile,
+		Target:     config.Target,
+	}
+
+	for key, value := range config.Args {
+		opts.BuildArgs = append(opts.BuildArgs, dagger.BuildArg{
+			Name:  key,
+			Value: value,
+		})
+	}
+
+	buildContext, err := utils.FilterBuildContext(ctx, t.b.Dir.Directory(config.Context), config.Dockerfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter build context: %w", err)
+	}
+
+	if len(config.Platforms) == 0 {
+		return []*dagger.Container{buildContext.DockerBuild(opts)}, nil
+	}
+
+	ctrs := []*dagger.Container{}
+	for _, platform := range config.Platforms {
+		platformOpts := opts
+		platformOpts.Platform = dagger.Platform(platform)
+
+		ctrs = append(ctrs, buildContext.DockerBuild(platformOpts))
+	}
+
+	return ctrs, nil
+}
+
+// Invoke builds every target of the function.
+//
+// Returns one container per target and platform.
+func (t *targetFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	bakeObj, err := t.b.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	// Loads the variables set by the user, others keep their default.
+	values := map[string]cty.Value{}
+	for _, variable := range t.variables() {
+		argName := utils.FormatEnvVariableName(variable.Name())
+		if input[argName] == nil {
+			continue
+		}
+
+		switch variable.Type() {
+		case bake.VariableTypeBool:
+			values[variable.Name()] = variable.Value(utils.LoadArgument[bool](argName, input))
+		case bake.VariableTypeNumber:
+			values[variable.Name()] = variable.Value(utils.LoadArgument[int64](argName, input))
+		default:
+			values[variable.Name()] = variable.Value(utils.LoadArgument[string](argName, input))
+		}
+	}
+
+	targets, err := t.targets()
+	if err != nil {
+		return nil, err
+	}
+
+	fct := &targetFunc{b: bakeObj, name: t.name, fnName: t.fnName, group: t.group}
+
+	ctrs := []*dagger.Container{}
+	for _, target := range targets {
+		targetCtrs, err := fct.build(ctx, target, values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build target %s: %w", target.Name(), err)
+		}
+
+		ctrs = append(ctrs, targetCtrs...)
+	}
+
+	return ctrs, nil
+}
+
+// Arguments returns the variables used by the targets as function arguments.
+//
+// Their type is inferred from their default value.
+func (t *targetFunc) Arguments() []*object.FunctionArg {
+	defaults, err := t.b.bake.Defaults()
+	if err != nil {
+		defaults = map[string]cty.Value{}
+	}
+
+	args := []*object.FunctionArg{}
+	for _, variable := range t.variables() {
+		argOpts := dagger.FunctionWithArgOpts{
+			Description: variable.Description(),
+		}
+
+		if argOpts.Description == "" {
+			argOpts.Description = fmt.Sprintf("Set %s variable", variable.Name())
+		}
+
+		if value, exist := variable.Default(defaults); exist {
+			argOpts.DefaultValue = utils.LoadDefaultValue(value)
+		}
+
+		kind := dagger.TypeDefKindStringKind
+		switch variable.Type() {
+		case bake.VariableTypeBool:
+			kind = dagger.TypeDefKindBooleanKind
+		case bake.VariableTypeNumber:
+			kind = dagger.TypeDefKindIntegerKind
+		}
+
+		args = append(args, &object.FunctionArg{
+			Name: utils.FormatEnvVariableName(variable.Name()),
+			Type: dag.TypeDef().WithKind(kind).WithOptional(true),
+			Opts: argOpts,
+		})
+	}
+
+	return args
+}
+
+// AddTypeDefToObject adds the function definition of the target or group to
+// the given Dagger module's object.
+func (t *targetFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	description := fmt.Sprintf("Build %s target", t.name)
+	if t.group {
+		description = fmt.Sprintf("Build targets of %s group", t.name)
+	}
+
+	typedef := dag.Function(t.fnName, dag.TypeDef().WithListOf(dag.TypeDef().WithObject("Container"))).
+		WithDescription(description)
+
+	for _, arg := range t.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
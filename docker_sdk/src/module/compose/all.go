@@ -9,98 +9,227 @@ import (
 	"dagger.io/dagger/dag"
 	"dagger.io/dockersdk/module/object"
 	"dagger.io/dockersdk/module/proxy"
+	"dagger.io/dockersdk/utils"
 )
 
-// allFunc is a function that starts all services using
-// a proxy module to group them together.
+const (
+	// proxyProtocolArgName is the name of the argument setting how the proxy
+	// forwards the TCP ports of a service.
+	proxyProtocolArgName = "proxyProtocol"
+
+	// proxyProtocolEnumName is the name of the enum of the proxy protocols.
+	proxyProtocolEnumName = "ProxyProtocol"
+
+	proxyProtocolHttp = "HTTP"
+	proxyProtocolTcp  = "TCP"
+
+	// proxyBackendArgName is the name of the argument selecting the proxy
+	// backend of "All".
+	proxyBackendArgName = "proxy"
+
+	// proxyBackendEnumName is the name of the enum of the proxy backends.
+	proxyBackendEnumName = "ProxyBackend"
+
+	proxyBackendForwarder = "FORWARDER"
+	proxyBackendNginx     = "NGINX"
+)
+
+// allFunc is a function that starts all services enabled by the active
+// profiles using a proxy to group them together.
 //
-// It MUST be registered AFTER all services have been registered.
-// The proxy is a simple duplication of: github.com/kpenfound/dagger-modules/proxy@v0.2.5 module.
+// It reads the service functions of Compose for its arguments.
+// The proxy is either a native Go forwarder or an Nginx proxy, a simple
+// duplication of: github.com/kpenfound/dagger-modules/proxy@v0.2.5 module,
+// kept as a fallback.
 type allFunc struct {
 	c *Compose
 }
 
-// up creates a proxy module with the given services.
-func (u *allFunc) up(services []*proxy.Service) *dagger.Container {
-	proxy := proxy.New()
+// up creates a proxy with the given backend and services.
+func (u *allFunc) up(backend string, services []*proxy.Service) *dagger.Container {
+	var p proxy.Backend = proxy.NewForwarder()
+	if backend == proxyBackendNginx {
+		p = proxy.New()
+	}
 
 	for _, service := range services {
-		proxy = proxy.WithService(service)
+		p = p.WithService(service)
 	}
 
-	return proxy.Service()
+	return p.Service()
 }
 
-// Invoke executes the "All" function with the given state and input arguments.
-func (u *allFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+// services starts every service enabled by the active profiles, reusing
+// the ones already running.
+func (u *allFunc) services(ctx context.Context, state object.State, input object.InputArgs) ([]*proxy.Service, error) {
 	compose, err := u.c.load(state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load object state: %w", err)
 	}
 
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
 	services := []*proxy.Service{}
-	for _, service := range u.c.dockercompose.Services() {
-		if u.c.runningServices[service.Name()] != nil {
-			fmt.Printf("service %s is already running ; exposing it to the proxy\n", service.Name())
+	for _, service := range compose.activeServices() {
+		service := &serviceFunc{c: compose, service: service, asDep: true}
+		key := service.key(input)
+
+		running := compose.registry.get(key)
+		if running != nil {
+			// One-shot services already ran as a dependency.
+			if running.service == nil {
+				continue
+			}
+
+			fmt.Printf("service %s is already running ; exposing it to the proxy\n", service.service.Name())
 
-			services = append(services, u.c.runningServices[service.Name()])
+			services = append(services, running.service)
 
 			continue
 		}
 
-		fmt.Printf("service %s is not running yet; starting it\n", service.Name())
+		fmt.Printf("service %s is not running yet; starting it\n", service.service.Name())
 
-		service := &serviceFunc{c: compose, service: service, asDep: true}
-
-		serviceCtr, err := service.ToService(ctx, state, input)
+		ctr, resolved, err := service.ToContainer(ctx, state, input)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get service %s: %w", service.service.Name(), err)
 		}
 
-		services = append(services, serviceCtr)
-		u.c.runningServices[service.service.Name()] = serviceCtr
+		ctr, err = resolved.withHealthcheck(ctx, ctr)
+		if err != nil {
+			return nil, err
+		}
+
+		running = &runningService{service: resolved.asService(ctr, input)}
+		compose.registry.set(key, running)
+
+		services = append(services, running.service)
 	}
 
-	return (*allFunc).up(
-		&allFunc{c: compose},
-		services,
-	), nil
+	return services, nil
 }
 
-// Arguments is a placeholder method not invoked for this function
-// required to implements the object.Function interface.
-//
-// This function should never be called for this function.
-func (u *allFunc) Arguments() []*object.FunctionArg {
-	return nil
+// proxy starts every service enabled by the active profiles and returns the
+// proxy container exposing them with the started services.
+func (u *allFunc) proxy(ctx context.Context, state object.State, input object.InputArgs) (*dagger.Container, []*proxy.Service, error) {
+	services, err := u.services(ctx, state, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return u.up(proxyBackend(input), services), services, nil
 }
 
-// AddTypeDefToObject adds "All" function definition to the given Dagger module's object.
-func (u *allFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+// proxyBackend returns the proxy backend selected by the input arguments,
+// the forwarder by default.
+func proxyBackend(input object.InputArgs) string {
+	if input[proxyBackendArgName] != nil {
+		return utils.LoadArgument[string](proxyBackendArgName, input)
+	}
+
+	return proxyBackendForwarder
+}
+
+// Invoke executes the "All" function with the given state and input arguments.
+func (u *allFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	ctr, _, err := u.proxy(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctr, nil
+}
+
+// serviceArguments returns the arguments of every service prefixed by its
+// name.
+func (u *allFunc) serviceArguments() []*object.FunctionArg {
 	args := []*object.FunctionArg{}
 
-	serviceNames := []string{}
-	for name, service := range u.c.funcMap {
-		serviceNames = append(serviceNames, name)
+	for _, service := range u.c.dockercompose.Services() {
+		fct := u.c.getServiceFunc(service.Name())
+		if fct == nil {
+			continue
+		}
 
-		serviceArgs := service.Arguments()
-		for _, arg := range serviceArgs {
+		for _, arg := range fct.Arguments() {
 			args = append(args, &object.FunctionArg{
 				// Prefix the argument name with the service name to avoid colission
-				Name: fmt.Sprintf("%s_%s", name, arg.Name),
+				Name: fmt.Sprintf("%s_%s", service.Name(), arg.Name),
 				Type: arg.Type,
 				Opts: arg.Opts,
 			})
 		}
 	}
 
+	return args
+}
+
+// Arguments returns the arguments of every service prefixed by its name, the
+// proxy protocol of every service and the proxy backend.
+//
+// They are shared by all the functions starting the whole stack.
+func (u *allFunc) Arguments() []*object.FunctionArg {
+	args := u.serviceArguments()
+
+	// Add the proxy protocol of each service.
+	for _, service := range u.c.dockercompose.Services() {
+		args = append(args, &object.FunctionArg{
+			Name: fmt.Sprintf("%s_%s", service.Name(), proxyProtocolArgName),
+			Type: dag.TypeDef().WithEnum(proxyProtocolEnumName).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: fmt.Sprintf("Protocol used by the proxy to forward the TCP ports of %s, defaults to the compose file's", service.Name()),
+			},
+		})
+	}
+
+	args = append(args, &object.FunctionArg{
+		Name: proxyBackendArgName,
+		Type: dag.TypeDef().WithEnum(proxyBackendEnumName).WithOptional(true),
+		Opts: dagger.FunctionWithArgOpts{
+			Description:  "Proxy exposing the services, the Nginx proxy supports HTTP forwarding",
+			DefaultValue: utils.LoadDefaultValue(proxyBackendForwarder),
+		},
+	})
+
+	return args
+}
+
+// serviceNames returns the names of the services enabled by the active
+// profiles, for the functions descriptions.
+func (u *allFunc) serviceNames() string {
+	names := []string{}
+	for _, service := range u.c.activeServices() {
+		names = append(names, service.Name())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// AddTypeDefToObject adds "All" function definition to the given Dagger module's object.
+func (u *allFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
 	typedef := dag.Function("All", dag.TypeDef().WithObject("Container")).
-		WithDescription(fmt.Sprintf("Start all service containers (%s)", strings.Join(serviceNames, ", ")))
+		WithDescription(fmt.Sprintf("Start all service containers enabled by the active profiles (%s)", u.serviceNames()))
 
-	for _, arg := range args {
+	for _, arg := range u.Arguments() {
 		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
 	}
 
 	return mod, obj.
 		WithFunction(typedef)
 }
+
+// withProxyEnums registers the enums of the proxy arguments to the given
+// Dagger module.
+func withProxyEnums(mod *dagger.Module) *dagger.Module {
+	return mod.
+		WithEnum(dag.TypeDef().
+			WithEnum(proxyProtocolEnumName).
+			WithEnumValue(proxyProtocolHttp).
+			WithEnumValue(proxyProtocolTcp)).
+		WithEnum(dag.TypeDef().
+			WithEnum(proxyBackendEnumName).
+			WithEnumValue(proxyBackendForwarder).
+			WithEnumValue(proxyBackendNginx))
+}
@@ -0,0 +1,81 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+)
+
+// ComposeEnvVariable describes an environment variable of a compose service,
+// returned by the "Environment" function.
+//
+// Its fields are resolved by Dagger from the returned value, so it doesn't
+// have any function.
+type ComposeEnvVariable struct {
+	// VariableName is the name of the variable, resolved as the "Name" field
+	// since Name is the name of the object.
+	VariableName string `json:"Name"`
+
+	// Value is the value of the variable, empty if it's a secret.
+	Value string
+
+	// Secret is true if the variable has no value in the compose file, so
+	// it's passed as a secret argument.
+	Secret bool
+}
+
+// Name returns the name of the object: "ComposeEnvVariable".
+func (e *ComposeEnvVariable) Name() string {
+	return "ComposeEnvVariable"
+}
+
+// Description provides a brief description of ComposeEnvVariable.
+func (e *ComposeEnvVariable) Description() string {
+	return "Environment variable of a compose service"
+}
+
+// New creates a new empty ComposeEnvVariable.
+func (e *ComposeEnvVariable) New(input object.InputArgs) object.Object {
+	return &ComposeEnvVariable{}
+}
+
+// AddTypeDef adds the module type definition for this object with all its
+// fields.
+func (e *ComposeEnvVariable) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().
+			WithObject(e.Name()).
+			WithField("Name", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Name of the variable",
+			}).
+			WithField("Value", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Value of the variable, empty if it's a secret",
+			}).
+			WithField("Secret", dag.TypeDef().WithKind(dagger.TypeDefKindBooleanKind), dagger.TypeDefWithFieldOpts{
+				Description: "Whether the variable has no value in the compose file and is passed as a secret argument",
+			})
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new ComposeEnvVariable from a saved state.
+func (e *ComposeEnvVariable) Load(state object.State) (object.Object, error) {
+	result := &ComposeEnvVariable{}
+
+	err := json.Unmarshal(state, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	return result, nil
+}
+
+// Invoke returns an error since ComposeEnvVariable doesn't have any function.
+func (e *ComposeEnvVariable) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	return nil, fmt.Errorf("unknown function %s", fnName)
+}
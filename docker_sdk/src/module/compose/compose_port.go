@@ -0,0 +1,97 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+)
+
+// ComposePort describes a port of a compose service, returned by the "Ports"
+// function.
+//
+// Its fields are resolved by Dagger from the returned value, so it doesn't
+// have any function.
+type ComposePort struct {
+	// Published is the port exposed to the host, the target port if the port
+	// isn't published.
+	Published int
+
+	// Target is the port the service's container listens on.
+	Target int
+
+	// Protocol is the transport protocol of the port: tcp or udp.
+	Protocol string
+
+	// Http is true if the port serves HTTP.
+	Http bool
+}
+
+// newComposePort describes the given port.
+func newComposePort(port *dockercompose.Port) *ComposePort {
+	return &ComposePort{
+		Published: port.Published(),
+		Target:    port.Target(),
+		Protocol:  string(port.Protocol()),
+		Http:      port.Http(),
+	}
+}
+
+// Name returns the name of the object: "ComposePort".
+func (p *ComposePort) Name() string {
+	return "ComposePort"
+}
+
+// Description provides a brief description of ComposePort.
+func (p *ComposePort) Description() string {
+	return "Port of a compose service"
+}
+
+// New creates a new empty ComposePort.
+func (p *ComposePort) New(input object.InputArgs) object.Object {
+	return &ComposePort{}
+}
+
+// AddTypeDef adds the module type definition for this object with all its
+// fields.
+func (p *ComposePort) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().
+			WithObject(p.Name()).
+			WithField("Published", dag.TypeDef().WithKind(dagger.TypeDefKindIntegerKind), dagger.TypeDefWithFieldOpts{
+				Description: "Port exposed to the host, the target port if the port isn't published",
+			}).
+			WithField("Target", dag.TypeDef().WithKind(dagger.TypeDefKindIntegerKind), dagger.TypeDefWithFieldOpts{
+				Description: "Port the service's container listens on",
+			}).
+			WithField("Protocol", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Transport protocol of the port: tcp or udp",
+			}).
+			WithField("Http", dag.TypeDef().WithKind(dagger.TypeDefKindBooleanKind), dagger.TypeDefWithFieldOpts{
+				Description: "Whether the port serves HTTP",
+			})
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new ComposePort from a saved state.
+func (p *ComposePort) Load(state object.State) (object.Object, error) {
+	result := &ComposePort{}
+
+	err := json.Unmarshal(state, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	return result, nil
+}
+
+// Invoke returns an error since ComposePort doesn't have any function.
+func (p *ComposePort) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	return nil, fmt.Errorf("unknown function %s", fnName)
+}
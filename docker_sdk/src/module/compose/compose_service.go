@@ -0,0 +1,142 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+)
+
+// ComposeService describes a service of the compose project, returned by the
+// "Services" function.
+//
+// Its fields are resolved by Dagger from the returned value, so it doesn't
+// have any function.
+type ComposeService struct {
+	// ServiceName is the name of the service, resolved as the "Name" field
+	// since Name is the name of the object.
+	ServiceName string `json:"Name"`
+
+	// ContainerName is the container name of the service, its name if unset.
+	ContainerName string
+
+	// Image is the image of the service, empty if it's built from a
+	// Dockerfile.
+	Image string
+
+	// BuildContext is the build context of the service relative to the
+	// compose directory, empty if it uses an image.
+	BuildContext string
+
+	// Dockerfile is the Dockerfile of the service relative to its build
+	// context, empty if it uses an image.
+	Dockerfile string
+
+	// Profiles are the profiles the service belongs to.
+	Profiles []string
+
+	// DependsOn are the services the service directly depends on.
+	DependsOn []string
+
+	// Networks are the networks the service is attached to.
+	Networks []string
+}
+
+// newComposeService describes the given service.
+func newComposeService(service *dockercompose.Service) *ComposeService {
+	result := &ComposeService{
+		ServiceName:   service.Name(),
+		ContainerName: service.ContainerName(),
+		Profiles:      []string{},
+		DependsOn:     []string{},
+		Networks:      service.Networks(),
+	}
+
+	source := service.Source()
+	switch source.Type {
+	case dockercompose.SourceTypeImage:
+		result.Image = source.Image.Ref
+	case dockercompose.SourceTypeDockerfile:
+		result.BuildContext = source.Dockerfile.Context
+		result.Dockerfile = source.Dockerfile.Dockerfile
+	}
+
+	result.Profiles = append(result.Profiles, service.Profiles()...)
+
+	for _, dependency := range service.Dependencies() {
+		result.DependsOn = append(result.DependsOn, dependency.Name())
+	}
+
+	return result
+}
+
+// Name returns the name of the object: "ComposeService".
+func (s *ComposeService) Name() string {
+	return "ComposeService"
+}
+
+// Description provides a brief description of ComposeService.
+func (s *ComposeService) Description() string {
+	return "Service of a compose project"
+}
+
+// New creates a new empty ComposeService.
+func (s *ComposeService) New(input object.InputArgs) object.Object {
+	return &ComposeService{}
+}
+
+// AddTypeDef adds the module type definition for this object with all its
+// fields.
+func (s *ComposeService) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().
+			WithObject(s.Name()).
+			WithField("Name", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Name of the service",
+			}).
+			WithField("ContainerName", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Container name of the service, its name if unset",
+			}).
+			WithField("Image", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Image of the service, empty if it's built from a Dockerfile",
+			}).
+			WithField("BuildContext", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Build context of the service relative to the compose directory, empty if it uses an image",
+			}).
+			WithField("Dockerfile", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Dockerfile of the service relative to its build context, empty if it uses an image",
+			}).
+			WithField("Profiles", dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)), dagger.TypeDefWithFieldOpts{
+				Description: "Profiles the service belongs to, empty if it's always enabled",
+			}).
+			WithField("DependsOn", dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)), dagger.TypeDefWithFieldOpts{
+				Description: "Services the service directly depends on",
+			}).
+			WithField("Networks", dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)), dagger.TypeDefWithFieldOpts{
+				Description: "Networks the service is attached to",
+			})
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new ComposeService from a saved state.
+func (s *ComposeService) Load(state object.State) (object.Object, error) {
+	result := &ComposeService{}
+
+	err := json.Unmarshal(state, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	return result, nil
+}
+
+// Invoke returns an error since ComposeService doesn't have any function.
+func (s *ComposeService) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	return nil, fmt.Errorf("unknown function %s", fnName)
+}
@@ -0,0 +1,95 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+)
+
+const (
+	// composeVolumeTypeBind is the type of a host path mounted in the service.
+	composeVolumeTypeBind = "bind"
+
+	// composeVolumeTypeVolume is the type of a named volume.
+	composeVolumeTypeVolume = "volume"
+
+	// composeVolumeTypeCache is the type of a bind mount whose host path
+	// doesn't exist, mounted as a cache volume.
+	composeVolumeTypeCache = "cache"
+
+	// composeVolumeTypeTmpfs is the type of a temporary directory.
+	composeVolumeTypeTmpfs = "tmpfs"
+)
+
+// ComposeVolume describes a volume of a compose service, returned by the
+// "Volumes" function.
+//
+// Its fields are resolved by Dagger from the returned value, so it doesn't
+// have any function.
+type ComposeVolume struct {
+	// Type is the type of the volume: bind, volume, cache or tmpfs.
+	Type string
+
+	// Source is the host path relative to the compose directory for bind
+	// mounts, the name of the volume otherwise. It's empty for tmpfs.
+	Source string
+
+	// Target is the path the volume is mounted at in the service's container.
+	Target string
+}
+
+// Name returns the name of the object: "ComposeVolume".
+func (v *ComposeVolume) Name() string {
+	return "ComposeVolume"
+}
+
+// Description provides a brief description of ComposeVolume.
+func (v *ComposeVolume) Description() string {
+	return "Volume of a compose service"
+}
+
+// New creates a new empty ComposeVolume.
+func (v *ComposeVolume) New(input object.InputArgs) object.Object {
+	return &ComposeVolume{}
+}
+
+// AddTypeDef adds the module type definition for this object with all its
+// fields.
+func (v *ComposeVolume) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().
+			WithObject(v.Name()).
+			WithField("Type", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Type of the volume: bind, volume, cache (bind mount of a missing host path) or tmpfs",
+			}).
+			WithField("Source", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Host path for bind mounts, name of the volume otherwise, empty for tmpfs",
+			}).
+			WithField("Target", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Path the volume is mounted at in the service's container",
+			})
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new ComposeVolume from a saved state.
+func (v *ComposeVolume) Load(state object.State) (object.Object, error) {
+	result := &ComposeVolume{}
+
+	err := json.Unmarshal(state, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	return result, nil
+}
+
+// Invoke returns an error since ComposeVolume doesn't have any function.
+func (v *ComposeVolume) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	return nil, fmt.Errorf("unknown function %s", fnName)
+}
@@ -0,0 +1,112 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/module/proxy"
+)
+
+// startAsDependency starts the service as a dependency of another service and
+// waits for the given condition.
+//
+// The service is only started once per call graph: if it's already in the
+// registry, it's reused and only its healthcheck is awaited if it isn't
+// healthy yet.
+//
+// It returns nil if the service ran to completion since it cannot be bound
+// to the dependent service.
+func (s *serviceFunc) startAsDependency(ctx context.Context, state object.State, input object.InputArgs, condition dockercompose.DependencyCondition) (*proxy.Service, error) {
+	key := s.key(input)
+
+	running := s.c.registry.get(key)
+	if running != nil {
+		fmt.Printf("service %s is already running ; reusing it\n", s.service.Name())
+	} else {
+		ctr, resolved, err := s.ToContainer(ctx, state, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert service %s to container: %w", s.service.Name(), err)
+		}
+
+		running = &runningService{}
+
+		if condition == dockercompose.DependencyConditionCompletedSuccessfully {
+			fmt.Printf("running service %s to completion\n", s.service.Name())
+
+			if err := s.runToCompletion(ctx, ctr); err != nil {
+				return nil, err
+			}
+		} else {
+			ctr, err = resolved.withHealthcheck(ctx, ctr)
+			if err != nil {
+				return nil, err
+			}
+
+			running.service = resolved.asService(ctr, input)
+		}
+
+		s.c.registry.set(key, running)
+	}
+
+	if condition == dockercompose.DependencyConditionHealthy && running.service != nil && !running.healthy {
+		if err := s.waitHealthy(ctx, running.service); err != nil {
+			return nil, err
+		}
+
+		running.healthy = true
+	}
+
+	return running.service, nil
+}
+
+// runToCompletion runs the service's command and returns an error if it
+// exits with a non-zero code.
+func (s *serviceFunc) runToCompletion(ctx context.Context, ctr *dagger.Container) error {
+	entrypoint, err := ctr.Entrypoint(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get entrypoint of service %s: %w", s.service.Name(), err)
+	}
+
+	args, err := ctr.DefaultArgs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get command of service %s: %w", s.service.Name(), err)
+	}
+
+	command := append(entrypoint, args...)
+	if len(command) == 0 {
+		return fmt.Errorf("service %s has no command to run", s.service.Name())
+	}
+
+	_, err = ctr.
+		WithExec(command, dagger.ContainerWithExecOpts{
+			InsecureRootCapabilities: s.service.Privileged(),
+		}).
+		Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("service %s did not complete successfully: %w", s.service.Name(), err)
+	}
+
+	return nil
+}
+
+// waitHealthy starts the service and waits for its healthcheck to succeed.
+//
+// The service runs its healthcheck next to its command (see withHealthcheck)
+// and Dagger waits for its health port when starting it, so it fails if the
+// healthcheck does.
+func (s *serviceFunc) waitHealthy(ctx context.Context, service *proxy.Service) error {
+	if s.service.Healthcheck() == nil {
+		return fmt.Errorf("service %s has no healthcheck, it cannot be awaited with %s", s.service.Name(), dockercompose.DependencyConditionHealthy)
+	}
+
+	fmt.Printf("waiting for service %s to be healthy\n", s.service.Name())
+
+	if _, err := service.Service.Start(ctx); err != nil {
+		return fmt.Errorf("service %s is unhealthy: %w", s.service.Name(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// mountedSecret is a secret to mount in the service's container.
+type mountedSecret struct {
+	ref    *dockercompose.FileReference
+	secret *dagger.Secret
+}
+
+// mountedConfig is a config to mount in the service's container.
+type mountedConfig struct {
+	ref  *dockercompose.FileReference
+	file *dagger.File
+}
+
+// configArgName returns the name of the argument of the given config.
+//
+// It's formatted like the environment variable arguments.
+func configArgName(ref *dockercompose.FileReference) string {
+	return utils.FormatEnvVariableName("config_" + ref.Name())
+}
+
+// secretArgument returns the argument of the given mounted secret.
+//
+// A secret backed by a file defaults to the file, a secret backed by an
+// environment variable is optional and defaults to its value.
+func secretArgument(ref *dockercompose.FileReference) *object.FunctionArg {
+	if ref.File() != "" {
+		return &object.FunctionArg{
+			Name: ref.Name(),
+			Type: dag.TypeDef().WithObject("File"),
+			Opts: dagger.FunctionWithArgOpts{
+				DefaultPath: ref.File(),
+				Description: fmt.Sprintf("Secret %s to mount at %s", ref.Name(), ref.Target()),
+			},
+		}
+	}
+
+	description := fmt.Sprintf("Secret %s to mount at %s", ref.Name(), ref.Target())
+	if ref.Environment() != "" {
+		description = fmt.Sprintf("%s (default to $%s)", description, ref.Environment())
+	}
+
+	return &object.FunctionArg{
+		Name: ref.Name(),
+		Type: dag.TypeDef().WithObject("Secret").WithOptional(ref.Environment() != ""),
+		Opts: dagger.FunctionWithArgOpts{
+			Description: description,
+		},
+	}
+}
+
+// configArgument returns the argument of the given config, nil for an
+// inline config.
+//
+// A config backed by a file defaults to the file, a config backed by an
+// environment variable defaults to its value.
+func configArgument(ref *dockercompose.FileReference) *object.FunctionArg {
+	description := fmt.Sprintf("Config %s to mount at %s", ref.Name(), ref.Target())
+
+	switch {
+	case ref.File() != "":
+		return &object.FunctionArg{
+			Name: configArgName(ref),
+			Type: dag.TypeDef().WithObject("File"),
+			Opts: dagger.FunctionWithArgOpts{
+				DefaultPath: ref.File(),
+				Description: description,
+			},
+		}
+	case ref.Environment() != "":
+		opts := dagger.FunctionWithArgOpts{
+			Description: fmt.Sprintf("%s (default to $%s)", description, ref.Environment()),
+		}
+
+		if value, exist := ref.EnvironmentValue(); exist {
+			opts.DefaultValue = utils.LoadDefaultValue(value)
+		}
+
+		return &object.FunctionArg{
+			Name: configArgName(ref),
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind).WithOptional(true),
+			Opts: opts,
+		}
+	}
+
+	return nil
+}
+
+// loadMountedSecret loads the given mounted secret from the input arguments.
+//
+// It returns nil if the secret is backed by an unset environment variable.
+//
+// This workaround is required since the secret's name
+// isn't the same as the identifier defined in the compose file.
+func (s *serviceFunc) loadMountedSecret(ctx context.Context, ref *dockercompose.FileReference, input object.InputArgs) (*dagger.Secret, error) {
+	argName := s.formatInputArgName(ref.Name())
+
+	if input[argName] == nil {
+		value, exist := ref.EnvironmentValue()
+		if !exist {
+			return nil, nil
+		}
+
+		return dag.SetSecret(ref.Name(), value), nil
+	}
+
+	var plaintext string
+	var err error
+
+	if ref.File() != "" {
+		plaintext, err = utils.LoadFileFromID([]byte(input[argName])).Contents(ctx)
+	} else {
+		plaintext, err = utils.LoadSecretFromID([]byte(input[argName])).Plaintext(ctx)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add secret value: %w", err)
+	}
+
+	return dag.SetSecret(ref.Name(), plaintext), nil
+}
+
+// loadConfig loads the given config from the input arguments.
+//
+// It returns nil if the config is backed by an unset environment variable.
+func (s *serviceFunc) loadConfig(ref *dockercompose.FileReference, input object.InputArgs) *dagger.File {
+	argName := s.formatInputArgName(configArgName(ref))
+
+	switch {
+	case ref.File() != "":
+		return utils.LoadFileFromID([]byte(input[argName]))
+	case ref.Environment() != "":
+		content, exist := ref.EnvironmentValue()
+		if input[argName] != nil {
+			content, exist = utils.LoadArgument[string](argName, input), true
+		}
+
+		if !exist {
+			return nil
+		}
+
+		return dag.Directory().WithNewFile(ref.Name(), content).File(ref.Name())
+	}
+
+	return dag.Directory().WithNewFile(ref.Name(), ref.Content()).File(ref.Name())
+}
@@ -0,0 +1,88 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+const (
+	// graphFormatArgName is the name of the argument selecting the format
+	// of the graph.
+	graphFormatArgName = "format"
+
+	// graphFormatEnumName is the name of the enum of the graph formats.
+	graphFormatEnumName = "GraphFormat"
+
+	graphFormatMermaid = "MERMAID"
+	graphFormatDot     = "DOT"
+)
+
+// graphFormats maps the graph format enum values to their dockercompose
+// format.
+var graphFormats = map[string]dockercompose.GraphFormat{
+	graphFormatMermaid: dockercompose.GraphFormatMermaid,
+	graphFormatDot:     dockercompose.GraphFormatDot,
+}
+
+// graphFunc is a function that returns the dependency graph of the services
+// as text, to be included in documentation.
+type graphFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "Graph" function with the given state and input arguments.
+func (g *graphFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	compose, err := g.c.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
+	format := graphFormatMermaid
+	if input[graphFormatArgName] != nil {
+		format = utils.LoadArgument[string](graphFormatArgName, input)
+	}
+
+	return compose.dockercompose.Graph(graphFormats[format])
+}
+
+// Arguments returns the format of the graph.
+func (g *graphFunc) Arguments() []*object.FunctionArg {
+	return []*object.FunctionArg{
+		{
+			Name: graphFormatArgName,
+			Type: dag.TypeDef().WithEnum(graphFormatEnumName).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description:  "Format of the graph",
+				DefaultValue: utils.LoadDefaultValue(graphFormatMermaid),
+			},
+		},
+	}
+}
+
+// AddTypeDefToObject adds "Graph" function definition and its format enum to
+// the given Dagger module's object.
+func (g *graphFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	mod = mod.WithEnum(dag.TypeDef().
+		WithEnum(graphFormatEnumName).
+		WithEnumValue(graphFormatMermaid).
+		WithEnumValue(graphFormatDot))
+
+	typedef := dag.Function("Graph", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)).
+		WithDescription("Return the dependency graph of the services as Mermaid or DOT text")
+
+	for _, arg := range g.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
@@ -0,0 +1,105 @@
+package compose
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+)
+
+const (
+	// healthcheckPath is the path of the healthcheck binary in the service's
+	// container.
+	healthcheckPath = "/.dockersdk/healthcheck"
+
+	// healthcheckPort is the port accepting connections once the service is
+	// healthy.
+	healthcheckPort = 65531
+)
+
+// healthcheckSource is the source of the healthcheck binary.
+//
+//go:embed healthcheck/main.go
+var healthcheckSource string
+
+// healthcheckBinary builds the binary running a command and its healthcheck.
+func healthcheckBinary() *dagger.File {
+	return dag.Container().
+		From("golang:1.23.2-alpine").
+		WithWorkdir("/src").
+		WithNewFile("/src/main.go", healthcheckSource).
+		WithEnvVariable("CGO_ENABLED", "0").
+		WithExec([]string{"go", "build", "-o", "/src/healthcheck", "main.go"}).
+		File("/src/healthcheck")
+}
+
+// awaitedHealthy returns true if a service depends on the service with the
+// given name being healthy.
+func (c *Compose) awaitedHealthy(name string) bool {
+	for _, service := range c.dockercompose.Services() {
+		for _, dependency := range service.Dependencies() {
+			if dependency.Name() == name && dependency.Condition() == dockercompose.DependencyConditionHealthy {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// withHealthcheck runs the service's command through the healthcheck binary
+// if another service waits for it to be healthy.
+//
+// The healthcheck test runs in the service's container, next to its
+// command, so it can probe it on localhost like in Docker. The health port
+// is exposed and only accepts connections once the test succeeded: Dagger
+// waits for exposed ports when starting a service, so starting it waits for
+// its health and fails if it's unhealthy.
+func (s *serviceFunc) withHealthcheck(ctx context.Context, ctr *dagger.Container) (*dagger.Container, error) {
+	healthcheck := s.service.Healthcheck()
+	if healthcheck == nil || !s.c.awaitedHealthy(s.service.Name()) {
+		return ctr, nil
+	}
+
+	entrypoint, err := ctr.Entrypoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entrypoint of service %s: %w", s.service.Name(), err)
+	}
+
+	args, err := ctr.DefaultArgs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command of service %s: %w", s.service.Name(), err)
+	}
+
+	command := append(entrypoint, args...)
+	if len(command) == 0 {
+		return nil, fmt.Errorf("service %s has no command to run", s.service.Name())
+	}
+
+	test, err := json.Marshal(healthcheck.Test())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode healthcheck of service %s: %w", s.service.Name(), err)
+	}
+
+	return ctr.
+		WithFile(healthcheckPath, healthcheckBinary()).
+		WithExposedPort(healthcheckPort, dagger.ContainerWithExposedPortOpts{
+			Description: "Accepts connections once the service is healthy",
+		}).
+		WithEntrypoint([]string{
+			healthcheckPath,
+			"-test", string(test),
+			"-port", strconv.Itoa(healthcheckPort),
+			"-interval", healthcheck.Interval().String(),
+			"-timeout", healthcheck.Timeout().String(),
+			"-start-period", healthcheck.StartPeriod().String(),
+			"-retries", strconv.Itoa(healthcheck.Retries()),
+			"--",
+		}).
+		WithDefaultArgs(command), nil
+}
@@ -0,0 +1,187 @@
+// Healthcheck runs a command and its Docker healthcheck next to it.
+//
+// Usage:
+//
+//	healthcheck [flags] -- <command>...
+//
+// The command is run as is. The healthcheck test runs every interval in the
+// same container, so it can probe the command on localhost like in Docker.
+// Once a test succeeds, the port given by -port accepts connections so the
+// command can be awaited like any exposed port.
+// Failed tests during the start period don't count, once -retries tests
+// failed in a row, the command is killed and healthcheck exits with an
+// error.
+//
+// It only depends on the standard library so it can be built from this
+// single file.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// healthcheck is the configuration of the healthcheck.
+type healthcheck struct {
+	// test is the command checking the health.
+	test []string
+
+	// port is the port accepting connections once healthy.
+	port int
+
+	// interval is the time between two tests.
+	interval time.Duration
+
+	// timeout is the time after which a test is considered failed.
+	timeout time.Duration
+
+	// startPeriod is the time during which failed tests don't count.
+	startPeriod time.Duration
+
+	// retries is the number of failed tests in a row after which the
+	// command is unhealthy.
+	retries int
+}
+
+// wait runs the test every interval until it succeeds, then accepts
+// connections on the health port.
+//
+// Returns an error if the command is unhealthy.
+func (h *healthcheck) wait() error {
+	startPeriodEnd := time.Now().Add(h.startPeriod)
+	failures := 0
+
+	for {
+		time.Sleep(h.interval)
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		output, err := exec.CommandContext(ctx, h.test[0], h.test[1:]...).CombinedOutput()
+		cancel()
+
+		if err == nil {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", h.port))
+			if err != nil {
+				return fmt.Errorf("failed to listen on health port %d: %w", h.port, err)
+			}
+
+			go accept(listener)
+
+			log.Printf("healthy")
+
+			return nil
+		}
+
+		if time.Now().After(startPeriodEnd) {
+			failures++
+		}
+
+		log.Printf("test failed (%d/%d): %v: %s", failures, h.retries, err, output)
+
+		if failures >= h.retries {
+			return fmt.Errorf("unhealthy after %d failed tests", failures)
+		}
+	}
+}
+
+// accept closes every connection to the health port, it's only used to
+// tell the command is healthy.
+func accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		conn.Close()
+	}
+}
+
+func main() {
+	log.SetPrefix("healthcheck: ")
+	log.SetFlags(0)
+
+	h := &healthcheck{}
+	test := ""
+
+	flag.StringVar(&test, "test", "", "JSON array of the test command")
+	flag.IntVar(&h.port, "port", 0, "Port accepting connections once healthy")
+	flag.DurationVar(&h.interval, "interval", 30*time.Second, "Time between two tests")
+	flag.DurationVar(&h.timeout, "timeout", 30*time.Second, "Time after which a test is considered failed")
+	flag.DurationVar(&h.startPeriod, "start-period", 0, "Time during which failed tests don't count")
+	flag.IntVar(&h.retries, "retries", 3, "Number of failed tests in a row after which the command is unhealthy")
+	flag.Parse()
+
+	if err := json.Unmarshal([]byte(test), &h.test); err != nil || len(h.test) == 0 {
+		log.Fatalf("invalid test %q", test)
+	}
+
+	if flag.NArg() == 0 {
+		log.Fatal("no command to run")
+	}
+
+	h.retries = max(h.retries, 1)
+
+	cmd := exec.Command(flag.Arg(0), flag.Args()[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	unhealthy := make(chan error, 1)
+	go func() {
+		unhealthy <- h.wait()
+	}()
+
+	for {
+		select {
+		case err := <-exited:
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			os.Exit(0)
+		case err := <-unhealthy:
+			if err == nil {
+				// Healthy, keep running until the command exits.
+				unhealthy = nil
+
+				continue
+			}
+
+			cmd.Process.Kill()
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// freePort returns a TCP port free on the host.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestHealthcheckWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		test        []string
+		startPeriod time.Duration
+		wantHealthy bool
+	}{
+		{name: "healthy", test: []string{"true"}, wantHealthy: true},
+		{name: "unhealthy", test: []string{"false"}},
+		{name: "unhealthy after the start period", test: []string{"false"}, startPeriod: 30 * time.Millisecond},
+		{name: "timeout", test: []string{"sleep", "1"}},
+		{name: "shell", test: []string{"/bin/sh", "-c", "exit 0"}, wantHealthy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &healthcheck{
+				test:        tt.test,
+				port:        freePort(t),
+				interval:    10 * time.Millisecond,
+				timeout:     100 * time.Millisecond,
+				startPeriod: tt.startPeriod,
+				retries:     2,
+			}
+
+			err := h.wait()
+			if !tt.wantHealthy {
+				if err == nil {
+					t.Fatal("expected the command to be unhealthy")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", h.port))
+			if err != nil {
+				t.Fatalf("health port doesn't accept connections: %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
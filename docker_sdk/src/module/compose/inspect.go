@@ -0,0 +1,238 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// servicesFunc is a function that describes the services of the compose
+// project, whatever their profiles.
+type servicesFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "Services" function with the given state and input arguments.
+func (s *servicesFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	compose, err := s.c.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
+	services := compose.dockercompose.Services()
+	slices.SortFunc(services, func(a, b *dockercompose.Service) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	result := []*ComposeService{}
+	for _, service := range services {
+		result = append(result, newComposeService(service))
+	}
+
+	return result, nil
+}
+
+// Arguments returns no argument.
+func (s *servicesFunc) Arguments() []*object.FunctionArg {
+	return []*object.FunctionArg{}
+}
+
+// AddTypeDefToObject adds "Services" function definition to the given Dagger module's object.
+func (s *servicesFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Services", dag.TypeDef().WithListOf(dag.TypeDef().WithObject("ComposeService"))).
+		WithDescription("Describe the services of the compose project, whatever their profiles")
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// portsFunc is a function that describes the ports of a service.
+type portsFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "Ports" function with the given state and input arguments.
+func (p *portsFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	service, err := inspectedService(ctx, p.c, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*ComposePort{}
+	for _, port := range service.PublishedPorts() {
+		result = append(result, newComposePort(port))
+	}
+
+	return result, nil
+}
+
+// Arguments returns the service to describe.
+func (p *portsFunc) Arguments() []*object.FunctionArg {
+	return inspectArguments(p.c)
+}
+
+// AddTypeDefToObject adds "Ports" function definition to the given Dagger module's object.
+func (p *portsFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Ports", dag.TypeDef().WithListOf(dag.TypeDef().WithObject("ComposePort"))).
+		WithDescription("Describe the ports of a service with the ports they're published on")
+
+	for _, arg := range p.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// volumesFunc is a function that describes the volumes of a service.
+type volumesFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "Volumes" function with the given state and input arguments.
+func (v *volumesFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	service, err := inspectedService(ctx, v.c, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*ComposeVolume{}
+
+	volumes, caches := service.Volumes()
+	for _, volume := range volumes {
+		result = append(result, &ComposeVolume{
+			Type:   composeVolumeTypeBind,
+			Source: volume.Origin(),
+			Target: volume.Target(),
+		})
+	}
+
+	for _, cache := range caches {
+		volumeType := composeVolumeTypeCache
+		if cache.Named() {
+			volumeType = composeVolumeTypeVolume
+		}
+
+		result = append(result, &ComposeVolume{
+			Type:   volumeType,
+			Source: cache.Name(),
+			Target: cache.Path(),
+		})
+	}
+
+	for _, tmpfs := range service.Tmpfs() {
+		result = append(result, &ComposeVolume{
+			Type:   composeVolumeTypeTmpfs,
+			Target: tmpfs.Path(),
+		})
+	}
+
+	return result, nil
+}
+
+// Arguments returns the service to describe.
+func (v *volumesFunc) Arguments() []*object.FunctionArg {
+	return inspectArguments(v.c)
+}
+
+// AddTypeDefToObject adds "Volumes" function definition to the given Dagger module's object.
+func (v *volumesFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Volumes", dag.TypeDef().WithListOf(dag.TypeDef().WithObject("ComposeVolume"))).
+		WithDescription("Describe the volumes mounted in a service")
+
+	for _, arg := range v.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// environmentFunc is a function that describes the environment variables of
+// a service.
+type environmentFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "Environment" function with the given state and input arguments.
+func (e *environmentFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	service, err := inspectedService(ctx, e.c, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*ComposeEnvVariable{}
+
+	env, secrets := service.Environment()
+	for name, value := range env {
+		result = append(result, &ComposeEnvVariable{VariableName: name, Value: *value})
+	}
+
+	for _, name := range secrets {
+		result = append(result, &ComposeEnvVariable{VariableName: name, Secret: true})
+	}
+
+	slices.SortFunc(result, func(a, b *ComposeEnvVariable) int {
+		return strings.Compare(a.VariableName, b.VariableName)
+	})
+
+	return result, nil
+}
+
+// Arguments returns the service to describe.
+func (e *environmentFunc) Arguments() []*object.FunctionArg {
+	return inspectArguments(e.c)
+}
+
+// AddTypeDefToObject adds "Environment" function definition to the given Dagger module's object.
+func (e *environmentFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Environment", dag.TypeDef().WithListOf(dag.TypeDef().WithObject("ComposeEnvVariable"))).
+		WithDescription("Describe the environment variables of a service, sorted by name")
+
+	for _, arg := range e.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// inspectArguments returns the argument selecting the service to describe.
+func inspectArguments(c *Compose) []*object.FunctionArg {
+	names := []string{}
+	for _, service := range c.dockercompose.Services() {
+		names = append(names, service.Name())
+	}
+
+	return []*object.FunctionArg{
+		{
+			Name: "service",
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: fmt.Sprintf("Service to describe (%s)", strings.Join(names, ", ")),
+			},
+		},
+	}
+}
+
+// inspectedService returns the service given in input, after merging the
+// extra compose files.
+func inspectedService(ctx context.Context, c *Compose, state object.State, input object.InputArgs) (*dockercompose.Service, error) {
+	compose, err := c.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
+	return compose.dockercompose.GetService(utils.LoadArgument[string]("service", input))
+}
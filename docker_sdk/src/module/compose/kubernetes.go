@@ -0,0 +1,58 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/kubernetes"
+	"dagger.io/dockersdk/module/object"
+)
+
+// toKubernetesFunc is a function that converts the services enabled by the
+// active profiles to Kubernetes manifests.
+type toKubernetesFunc struct {
+	c *Compose
+}
+
+// Invoke executes the "ToKubernetes" function with the given state and input arguments.
+func (k *toKubernetesFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	compose, err := k.c.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
+	files, warnings, err := kubernetes.FromCompose(compose.dockercompose, compose.activeServices())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert compose services to Kubernetes: %w", err)
+	}
+
+	for _, warning := range warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+
+	dir := dag.Directory()
+	for filename, content := range files {
+		dir = dir.WithNewFile(filename, content)
+	}
+
+	return dir, nil
+}
+
+// Arguments returns no argument.
+func (k *toKubernetesFunc) Arguments() []*object.FunctionArg {
+	return []*object.FunctionArg{}
+}
+
+// AddTypeDefToObject adds "ToKubernetes" function definition to the given Dagger module's object.
+func (k *toKubernetesFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("ToKubernetes", dag.TypeDef().WithObject("Directory")).
+		WithDescription("Convert the services enabled by the active profiles to Kubernetes manifests, one file per service")
+
+	return mod, obj.WithFunction(typedef)
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
 	"dagger.io/dockersdk/codebase/dockercompose"
 	"dagger.io/dockersdk/module/object"
-	"dagger.io/dockersdk/module/proxy"
 	"dagger.io/dockersdk/utils"
+	"github.com/compose-spec/compose-go/types"
 )
 
 // Compose manages Docker Compose services.
@@ -18,38 +19,174 @@ type Compose struct {
 	// Dir is the directory containing the Docker Compose configuration.
 	Dir *dagger.Directory
 
+	// Profiles are the active profiles, services with other profiles are
+	// not started by "All".
+	Profiles []string
+
+	// Files are extra Docker Compose files, relative to Dir, merged on top
+	// of the project's files.
+	Files []string
+
 	// dockercompose is the Docker Compose configuration object.
 	dockercompose *dockercompose.DockerCompose
 
 	// funcMap maps service names to their associated functions for operations.
 	funcMap map[string]object.Function
 
-	// runningServices maps service names to their running services.
-	runningServices map[string]*proxy.Service
+	// registry holds the services started during the call.
+	registry *registry
+
+	// filesLoaded is true once Files are merged into dockercompose.
+	filesLoaded bool
 }
 
 // New creates a new Compose instance with the given directory and docker-compose file.
+//
+// A function is registered for each service, a service named after a fixed
+// function (e.g., `test`) is suffixed by "Service".
+//
+// Returns an error if services with different names end up with the same
+// function name (e.g., `web-api` and `web_api`).
 func New(
 	dir *dagger.Directory,
 	dockercomposeFile *dockercompose.DockerCompose,
-) *Compose {
+) (*Compose, error) {
 	c := &Compose{
-		Dir:             dir,
-		dockercompose:   dockercomposeFile,
-		funcMap:         make(map[string]object.Function),
-		runningServices: make(map[string]*proxy.Service),
+		Dir:           dir,
+		dockercompose: dockercomposeFile,
+		funcMap:       make(map[string]object.Function),
+		registry:      newRegistry(),
 	}
 
+	// Add functions to start and run commands in services.
+	all := &allFunc{c: c}
+	c.funcMap["All"] = all
+	c.funcMap["Up"] = &upFunc{all: all}
+	c.funcMap["UpServices"] = &upServicesFunc{all: all}
+	c.funcMap["Run"] = &runFunc{all: all}
+	c.funcMap["Exec"] = &execFunc{all: all}
+	c.funcMap["Test"] = &testFunc{all: all}
+
+	// Add a function to document the dependencies between services.
+	c.funcMap["Graph"] = &graphFunc{c: c}
+
+	// Add functions to describe the services.
+	c.funcMap["Services"] = &servicesFunc{c: c}
+	c.funcMap["Ports"] = &portsFunc{c: c}
+	c.funcMap["Volumes"] = &volumesFunc{c: c}
+	c.funcMap["Environment"] = &environmentFunc{c: c}
+
+	// Add a function to migrate the services to Kubernetes.
+	c.funcMap["ToKubernetes"] = &toKubernetesFunc{c: c}
+
+	// Dagger formats function names, so services are compared by their
+	// formatted name, case-insensitively.
+	fixed := map[string]bool{}
+	for name := range c.funcMap {
+		fixed[strings.ToLower(name)] = true
+	}
+
+	services := map[string]string{}
 	for _, service := range dockercomposeFile.Services() {
-		c.funcMap[service.Name()] = &serviceFunc{c: c, service: service, asDep: false}
+		fnName := service.Name()
+		if fixed[strings.ToLower(utils.FormatName(fnName))] {
+			fnName = utils.FormatName(fnName) + "Service"
+		}
+
+		key := strings.ToLower(utils.FormatName(fnName))
+		if existing, ok := services[key]; ok {
+			return nil, fmt.Errorf("%s and %s are both exposed as the %s function, rename one of them", existing, service.Name(), utils.FormatName(fnName))
+		}
+
+		services[key] = service.Name()
+		c.funcMap[fnName] = &serviceFunc{c: c, service: service, fnName: fnName, asDep: false}
+	}
+
+	return c, nil
+}
+
+// getServiceFunc returns the function of the service with the given name,
+// nil if there's none.
+func (c *Compose) getServiceFunc(name string) *serviceFunc {
+	for _, fct := range c.funcMap {
+		if service, ok := fct.(*serviceFunc); ok && service.service.Name() == name {
+			return service
+		}
 	}
 
-	// Add a function to start all services.
-	c.funcMap["All"] = &allFunc{c: c}
+	return nil
+}
+
+// Deps returns the objects returned by the Compose functions.
+func (c *Compose) Deps() map[string]object.Object {
+	testResult := &TestResult{}
+	composeService := &ComposeService{}
+	composePort := &ComposePort{}
+	composeVolume := &ComposeVolume{}
+	composeEnvVariable := &ComposeEnvVariable{}
+
+	return map[string]object.Object{
+		testResult.Name():         testResult,
+		composeService.Name():     composeService,
+		composePort.Name():        composePort,
+		composeVolume.Name():      composeVolume,
+		composeEnvVariable.Name(): composeEnvVariable,
+	}
+}
+
+// WithProfiles sets the active profiles of the Compose object.
+func (c *Compose) WithProfiles(profiles []string) *Compose {
+	c.Profiles = profiles
 
 	return c
 }
 
+// WithFiles sets the extra Docker Compose files to merge on top of the
+// project's files.
+func (c *Compose) WithFiles(files []string) *Compose {
+	c.Files = files
+
+	return c
+}
+
+// loadFiles merges the extra Docker Compose files into the project.
+//
+// It reads the files from Dir and does nothing if there's no extra file or
+// they're already merged.
+func (c *Compose) loadFiles(ctx context.Context) error {
+	if len(c.Files) == 0 || c.filesLoaded {
+		return nil
+	}
+
+	files := []types.ConfigFile{}
+	for _, filename := range c.Files {
+		content, err := c.Dir.File(filename).Contents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+
+		files = append(files, types.ConfigFile{
+			Filename: filename,
+			Content:  []byte(content),
+		})
+	}
+
+	dockercompose, err := c.dockercompose.WithFiles(ctx, files)
+	if err != nil {
+		return fmt.Errorf("failed to merge compose files: %w", err)
+	}
+
+	c.dockercompose = dockercompose
+	c.filesLoaded = true
+
+	return nil
+}
+
+// activeServices returns the services enabled by the active profiles.
+func (c *Compose) activeServices() []*dockercompose.Service {
+	return c.dockercompose.ActiveServices(c.Profiles)
+}
+
 // Name returns the name of the object: "Compose".
 func (c *Compose) Name() string {
 	return "Compose"
@@ -71,6 +208,7 @@ func (c *Compose) New(input object.InputArgs) object.Object {
 	return &Compose{
 		Dir:           dir,
 		dockercompose: c.dockercompose,
+		registry:      newRegistry(),
 	}
 }
 
@@ -79,6 +217,7 @@ func (c *Compose) New(input object.InputArgs) object.Object {
 func (c *Compose) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
 	return func(mod *dagger.Module) *dagger.Module {
 		object := dag.TypeDef().WithObject(c.Name())
+		mod = withProxyEnums(mod)
 
 		for _, fct := range c.funcMap {
 			mod, object = fct.AddTypeDefToObject(ctx, mod, object)
@@ -102,15 +241,28 @@ func (c *Compose) load(state object.State) (*Compose, error) {
 	}
 
 	cpyCompose := &Compose{
-		dockercompose:   c.dockercompose,
-		funcMap:         c.funcMap,
-		runningServices: c.runningServices,
+		dockercompose: c.dockercompose,
+		funcMap:       c.funcMap,
+		registry:      c.registry,
+		filesLoaded:   c.filesLoaded,
 	}
 
 	if parentMap["Dir"] != nil {
 		cpyCompose.Dir = dag.LoadDirectoryFromID(dagger.DirectoryID(parentMap["Dir"].(string)))
 	}
 
+	if profiles, ok := parentMap["Profiles"].([]interface{}); ok {
+		for _, profile := range profiles {
+			cpyCompose.Profiles = append(cpyCompose.Profiles, profile.(string))
+		}
+	}
+
+	if files, ok := parentMap["Files"].([]interface{}); ok {
+		for _, file := range files {
+			cpyCompose.Files = append(cpyCompose.Files, file.(string))
+		}
+	}
+
 	return cpyCompose, nil
 }
 
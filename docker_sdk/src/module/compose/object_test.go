@@ -0,0 +1,137 @@
+package compose
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"dagger.io/dockersdk/codebase/dockercompose"
+	"dagger.io/dockersdk/codebase/finder"
+	"github.com/compose-spec/compose-go/types"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantServices []string
+		wantErr      string
+	}{
+		{
+			name: "services",
+			content: `
+services:
+  api:
+    image: alpine
+  web-app:
+    image: alpine
+`,
+			wantServices: []string{"api", "web-app"},
+		},
+		{
+			name: "services named after fixed functions",
+			content: `
+services:
+  test:
+    image: alpine
+  up_services:
+    image: alpine
+  to-kubernetes:
+    image: alpine
+  Graph:
+    image: alpine
+`,
+			wantServices: []string{"GraphService", "TestService", "ToKubernetesService", "UpServicesService"},
+		},
+		{
+			name: "services named after fixed functions in another case",
+			content: `
+services:
+  ALL:
+    image: alpine
+  up:
+    image: alpine
+  toKubernetes:
+    image: alpine
+`,
+			wantServices: []string{"ALLService", "ToKubernetesService", "UpService"},
+		},
+		{
+			name: "services with the same function name in another case",
+			content: `
+services:
+  api:
+    image: alpine
+  API:
+    image: alpine
+`,
+			wantErr: "are both exposed as the",
+		},
+		{
+			name: "services with the same function name",
+			content: `
+services:
+  web-api:
+    image: alpine
+  web_api:
+    image: alpine
+`,
+			wantErr: "web-api and web_api are both exposed as the WebApi function",
+		},
+		{
+			name: "renamed service clashing with another service",
+			content: `
+services:
+  test:
+    image: alpine
+  test-service:
+    image: alpine
+`,
+			wantErr: "are both exposed as the TestService function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			finder, err := finder.New(dir, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dockercomposeFile, err := dockercompose.NewDockerCompose(context.Background(), dir, []types.ConfigFile{
+				{Filename: "docker-compose.yml", Content: []byte(tt.content)},
+			}, finder)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := New(nil, dockercomposeFile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			services := []string{}
+			for name, fct := range c.funcMap {
+				if _, ok := fct.(*serviceFunc); ok {
+					services = append(services, name)
+				}
+			}
+			slices.Sort(services)
+
+			if !slices.Equal(services, tt.wantServices) {
+				t.Errorf("New() service functions = %v, want %v", services, tt.wantServices)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package compose
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/module/proxy"
+)
+
+// runningService is a service started during a call.
+type runningService struct {
+	// service is the running service, nil if it ran to completion.
+	service *proxy.Service
+
+	// healthy is true once the service is known to be ready.
+	healthy bool
+}
+
+// registry holds the services started during a call so each service is only
+// started once per call graph, e.g., a service both dependencies of a
+// service depend on.
+//
+// Services are keyed by their name and resolved arguments.
+type registry struct {
+	// services maps service keys to their running service.
+	services map[string]*runningService
+}
+
+// newRegistry creates an empty registry.
+func newRegistry() *registry {
+	return &registry{
+		services: make(map[string]*runningService),
+	}
+}
+
+// get returns the running service with the given key, nil if it isn't
+// started yet.
+func (r *registry) get(key string) *runningService {
+	return r.services[key]
+}
+
+// set registers the running service with the given key.
+func (r *registry) set(key string, service *runningService) {
+	r.services[key] = service
+}
+
+// key returns the registry key of the service for the given input: its name
+// and a hash of its resolved arguments.
+//
+// Arguments are resolved without their service prefix, so the key is the
+// same whether the service is started by its own function or as a
+// dependency.
+func (s *serviceFunc) key(input object.InputArgs) string {
+	names := []string{proxyProtocolArgName}
+	for _, arg := range s.Arguments() {
+		names = append(names, arg.Name)
+	}
+
+	values := []string{}
+	for _, name := range names {
+		values = append(values, fmt.Sprintf("%s=%s", name, input[s.formatInputArgName(name)]))
+	}
+	sort.Strings(values)
+
+	hash := sha256.Sum256([]byte(strings.Join(values, "\n")))
+
+	return fmt.Sprintf("%s@%x", s.service.Name(), hash[:8])
+}
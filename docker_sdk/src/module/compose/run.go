@@ -0,0 +1,147 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/module/proxy"
+	"dagger.io/dockersdk/utils"
+)
+
+// runFunc is a function that runs a one-off command in a service after
+// starting its dependencies, like `docker compose run --rm`.
+type runFunc struct {
+	all *allFunc
+}
+
+// Invoke executes the "Run" function with the given state and input arguments.
+func (r *runFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	return runCommand(ctx, r.all, state, input, utils.LoadArgument[[]string]("args", input), nil)
+}
+
+// Arguments returns the service, the command and the arguments of every
+// service.
+func (r *runFunc) Arguments() []*object.FunctionArg {
+	return commandArguments(r.all, "args")
+}
+
+// AddTypeDefToObject adds "Run" function definition to the given Dagger module's object.
+func (r *runFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Run", dag.TypeDef().WithObject("Container")).
+		WithDescription("Run a one-off command in a service after starting its dependencies, returns the container after the command")
+
+	for _, arg := range r.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// execFunc is a function that runs a command against the whole stack, like
+// `docker compose exec`.
+//
+// Dagger cannot run a command inside a running service, so the command runs
+// in a new container of the service bound to every service enabled by the
+// active profiles.
+type execFunc struct {
+	all *allFunc
+}
+
+// Invoke executes the "Exec" function with the given state and input arguments.
+func (e *execFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	services, err := e.all.services(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return runCommand(ctx, e.all, state, input, utils.LoadArgument[[]string]("args", input), services)
+}
+
+// Arguments returns the service, the command and the arguments of every
+// service.
+func (e *execFunc) Arguments() []*object.FunctionArg {
+	return commandArguments(e.all, "args")
+}
+
+// AddTypeDefToObject adds "Exec" function definition to the given Dagger module's object.
+func (e *execFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Exec", dag.TypeDef().WithObject("Container")).
+		WithDescription(fmt.Sprintf("Run a command in a service bound to all services enabled by the active profiles (%s), returns the container after the command", e.all.serviceNames()))
+
+	for _, arg := range e.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// commandArguments returns the arguments selecting the service and the
+// command to run, named commandArgName, followed by the arguments of every
+// service prefixed by its name.
+func commandArguments(all *allFunc, commandArgName string) []*object.FunctionArg {
+	args := []*object.FunctionArg{
+		{
+			Name: "service",
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: fmt.Sprintf("Service to run the command in (%s)", all.serviceNames()),
+			},
+		},
+		{
+			Name: commandArgName,
+			Type: dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Command to run, it replaces the service's command and is passed to its entrypoint",
+			},
+		},
+	}
+
+	return append(args, all.serviceArguments()...)
+}
+
+// runCommand runs the command in a container of the service given in input,
+// bound to the given services.
+//
+// The command replaces the service's command, its exit code doesn't fail the
+// function so it can be retrieved from the returned container.
+func runCommand(ctx context.Context, all *allFunc, state object.State, input object.InputArgs, args []string, services []*proxy.Service) (*dagger.Container, error) {
+	compose, err := all.c.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, err
+	}
+
+	name := utils.LoadArgument[string]("service", input)
+
+	service, err := compose.dockercompose.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fct := &serviceFunc{c: compose, service: service, asDep: true}
+
+	ctr, _, err := fct.ToContainer(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, running := range services {
+		if running.Name != name && fct.canReach(running.Name) {
+			ctr = fct.withServiceBinding(ctr, running)
+		}
+	}
+
+	fmt.Printf("running %v in service %s\n", args, name)
+
+	return ctr.WithExec(args, dagger.ContainerWithExecOpts{
+		UseEntrypoint:            true,
+		InsecureRootCapabilities: service.Privileged(),
+		Expect:                   dagger.ReturnTypeAny,
+	}).Sync(ctx)
+}
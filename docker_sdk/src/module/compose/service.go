@@ -3,6 +3,7 @@ package compose
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
@@ -20,6 +21,10 @@ type serviceFunc struct {
 	// service represents the managed Docker Compose service.
 	service *dockercompose.Service
 
+	// fnName is the name of the function of the service, its name unless it
+	// clashes with a fixed function of Compose.
+	fnName string
+
 	// If set to true, the service will prefix the service name before
 	// looking for the input arguments
 	asDep bool
@@ -32,7 +37,8 @@ func (s *serviceFunc) container(
 	source *dockercompose.Source,
 	env map[string]string,
 	secretsEnv map[string]*dagger.Secret,
-	mountedSecrets map[string]*dagger.Secret,
+	mountedSecrets []*mountedSecret,
+	mountedConfigs []*mountedConfig,
 	mountedVolumes map[string]*dagger.Directory,
 	mountedFiles map[string]*dagger.File,
 	caches map[string]string,
@@ -53,13 +59,23 @@ func (s *serviceFunc) container(
 		}
 
 		for key, value := range source.Dockerfile.BuildArgs {
+			// Arguments without value are left to the Dockerfile's default.
+			if value == nil {
+				continue
+			}
+
 			buildOpts.BuildArgs = append(buildOpts.BuildArgs, dagger.BuildArg{
 				Name:  key,
 				Value: *value,
 			})
 		}
 
-		ctr = s.c.Dir.Directory(source.Dockerfile.Context).DockerBuild(buildOpts)
+		buildContext, err := utils.FilterBuildContext(ctx, s.c.Dir.Directory(source.Dockerfile.Context), source.Dockerfile.Dockerfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter build context: %w", err)
+		}
+
+		ctr = buildContext.DockerBuild(buildOpts)
 	default:
 		return nil, fmt.Errorf("unknown source type %s", source.Type)
 	}
@@ -69,6 +85,28 @@ func (s *serviceFunc) container(
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if serviceUser := s.service.User(); serviceUser != "" {
+		ctr = ctr.WithUser(serviceUser)
+		user = serviceUser
+	}
+
+	// Mounts are owned by the service's user, which may already be a
+	// "user:group" pair.
+	owner := fmt.Sprintf("%s:%s", user, user)
+	if strings.Contains(user, ":") {
+		owner = user
+	}
+
+	for key, value := range s.service.Labels() {
+		ctr = ctr.WithLabel(key, value)
+	}
+
+	for _, tmpfs := range s.service.Tmpfs() {
+		ctr = ctr.WithMountedTemp(tmpfs.Path(), dagger.ContainerWithMountedTempOpts{
+			Size: tmpfs.Size(),
+		})
+	}
+
 	if workdir := s.service.Workdir(); workdir != "" {
 		ctr = ctr.WithWorkdir(workdir)
 	}
@@ -81,12 +119,10 @@ func (s *serviceFunc) container(
 		ctr = ctr.WithSecretVariable(name, secret)
 	}
 
-	for name, secret := range mountedSecrets {
-		ctr = ctr.WithMountedSecret(fmt.Sprintf("/run/secrets/%s", name), secret)
-	}
-
 	for _, port := range s.service.Ports() {
-		ctr = ctr.WithExposedPort(port)
+		ctr = ctr.WithExposedPort(port.Target(), dagger.ContainerWithExposedPortOpts{
+			Protocol: networkProtocol(port.Protocol()),
+		})
 	}
 
 	// Get exposed ports
@@ -101,26 +137,61 @@ func (s *serviceFunc) container(
 			return nil, fmt.Errorf("failed to get exposed port: %w", err)
 		}
 
-		ctr = ctr.WithExposedPort(exposedPort)
+		protocol, err := port.Protocol(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exposed port protocol: %w", err)
+		}
+
+		ctr = ctr.WithExposedPort(exposedPort, dagger.ContainerWithExposedPortOpts{
+			Protocol: protocol,
+		})
 
-		s.service.WithExposedPort(exposedPort)
+		if protocol == dagger.NetworkProtocolUdp {
+			s.service.WithExposedPort(exposedPort, dockercompose.PortProtocolUdp)
+		} else {
+			s.service.WithExposedPort(exposedPort, dockercompose.PortProtocolTcp)
+		}
 	}
 
 	for path, volume := range mountedVolumes {
 		ctr = ctr.WithMountedDirectory(path, volume, dagger.ContainerWithMountedDirectoryOpts{
-			Owner: fmt.Sprintf("%s:%s", user, user),
+			Owner: owner,
+		})
+	}
+
+	for _, mounted := range mountedSecrets {
+		secretOwner := mounted.ref.Owner()
+		if secretOwner == "" {
+			secretOwner = owner
+		}
+
+		ctr = ctr.WithMountedSecret(mounted.ref.Target(), mounted.secret, dagger.ContainerWithMountedSecretOpts{
+			Owner: secretOwner,
+			Mode:  mounted.ref.Mode(),
+		})
+	}
+
+	for _, mounted := range mountedConfigs {
+		configOwner := mounted.ref.Owner()
+		if configOwner == "" {
+			configOwner = owner
+		}
+
+		ctr = ctr.WithFile(mounted.ref.Target(), mounted.file, dagger.ContainerWithFileOpts{
+			Owner:       configOwner,
+			Permissions: mounted.ref.Mode(),
 		})
 	}
 
 	for path, file := range mountedFiles {
 		ctr = ctr.WithMountedFile(path, file, dagger.ContainerWithMountedFileOpts{
-			Owner: fmt.Sprintf("%s:%s", user, user),
+			Owner: owner,
 		})
 	}
 
 	for name, target := range caches {
 		ctr = ctr.WithMountedCache(target, dag.CacheVolume(name), dagger.ContainerWithMountedCacheOpts{
-			Owner: fmt.Sprintf("%s:%s", user, user),
+			Owner: owner,
 		})
 	}
 
@@ -135,52 +206,110 @@ func (s *serviceFunc) container(
 	}
 
 	for _, service := range dependentServices {
-		ctr = ctr.
-			WithServiceBinding(service.Name, service.Service)
+		ctr = s.withServiceBinding(ctr, service)
 	}
 
 	return ctr.Sync(ctx)
 }
 
-// ToContainer converts the service into a configurable container.
-func (s *serviceFunc) ToContainer(ctx context.Context, state object.State, input object.InputArgs) (*dagger.Container, error) {
-	ctrRes, err := s.Invoke(ctx, state, input)
+// withServiceBinding binds the given service to the container of this
+// service by its name and the aliases it has on their shared networks.
+func (s *serviceFunc) withServiceBinding(ctr *dagger.Container, service *proxy.Service) *dagger.Container {
+	ctr = ctr.WithServiceBinding(service.Name, service.Service)
+
+	bound, err := s.c.dockercompose.GetService(service.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to invoke service %s: %w", s.service.Name(), err)
+		return ctr
 	}
 
-	ctr, ok := ctrRes.(*dagger.Container)
-	if !ok {
-		return nil, fmt.Errorf("expected container result, got %T", ctrRes)
+	for _, alias := range bound.AliasesFor(s.service) {
+		ctr = ctr.WithServiceBinding(alias, service.Service)
 	}
 
-	return ctr, nil
+	return ctr
 }
 
-// ToService converts a container into a service exposing necessary ports.
-func (s *serviceFunc) ToService(ctx context.Context, state object.State, input object.InputArgs) (*proxy.Service, error) {
-	ctr, err := s.ToContainer(ctx, state, input)
+// canReach returns true if this service shares a network with the given
+// service, it prints a warning otherwise.
+func (s *serviceFunc) canReach(name string) bool {
+	service, err := s.c.dockercompose.GetService(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert service %s to container: %w", s.service.Name(), err)
+		return false
 	}
 
+	if !s.service.CanReach(service) {
+		fmt.Printf("warning: service %s cannot reach %s over any shared network (%s and %s) ; not binding it\n",
+			s.service.Name(), name, strings.Join(s.service.Networks(), ", "), strings.Join(service.Networks(), ", "))
+
+		return false
+	}
+
+	return true
+}
+
+// ToContainer converts the service into a configurable container.
+//
+// It also returns the service function the container is built from, see
+// toContainer.
+func (s *serviceFunc) ToContainer(ctx context.Context, state object.State, input object.InputArgs) (*dagger.Container, *serviceFunc, error) {
+	ctr, resolved, err := s.toContainer(ctx, state, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to invoke service %s: %w", s.service.Name(), err)
+	}
+
+	return ctr, resolved, nil
+}
+
+// asService converts the service's container into a service exposing its
+// published ports.
+//
+// TCP ports are proxied as HTTP or streams according to the compose file,
+// unless the proxy protocol argument of the service is set.
+func (s *serviceFunc) asService(ctr *dagger.Container, input object.InputArgs) *proxy.Service {
 	service := &proxy.Service{
-		Service: ctr.AsService(dagger.ContainerAsServiceOpts{UseEntrypoint: true}),
+		Service: ctr.AsService(dagger.ContainerAsServiceOpts{
+			UseEntrypoint:            true,
+			InsecureRootCapabilities: s.service.Privileged(),
+		}),
 		Name:    s.service.Name(),
-		Alias:   s.service.ContainerName(),
+		Aliases: s.service.Aliases(),
 		Exposed: false,
 	}
 
-	ports := s.service.Ports()
-	if len(ports) == 0 {
-		return service, nil
+	proxyProtocol := ""
+	if input[s.formatInputArgName(proxyProtocolArgName)] != nil {
+		proxyProtocol = utils.LoadArgument[string](s.formatInputArgName(proxyProtocolArgName), input)
+	}
+
+	for _, port := range s.service.PublishedPorts() {
+		isHttp := port.Http()
+		switch proxyProtocol {
+		case proxyProtocolHttp:
+			isHttp = true
+		case proxyProtocolTcp:
+			isHttp = false
+		}
+
+		service.Ports = append(service.Ports, &proxy.Port{
+			Frontend: port.Published(),
+			Backend:  port.Target(),
+			Protocol: networkProtocol(port.Protocol()),
+			Http:     isHttp,
+		})
 	}
 
-	service.Frontend = ports[0]
-	service.Backend = ports[0]
-	service.Exposed = true
+	service.Exposed = len(service.Ports) != 0
+
+	return service
+}
+
+// networkProtocol converts a port protocol into its Dagger equivalent.
+func networkProtocol(protocol dockercompose.PortProtocol) dagger.NetworkProtocol {
+	if protocol == dockercompose.PortProtocolUdp {
+		return dagger.NetworkProtocolUdp
+	}
 
-	return service, nil
+	return dagger.NetworkProtocolTcp
 }
 
 // formatInputArgName formats the input argument name
@@ -196,28 +325,76 @@ func (s *serviceFunc) formatInputArgName(argName string) string {
 
 // Invoke returns the configured service container with given state and input arguments.
 func (s *serviceFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	ctr, _, err := s.toContainer(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctr, nil
+}
+
+// toContainer returns the configured service container with the service
+// function it's built from.
+//
+// The returned function holds the service merged with the extra compose
+// files and the ports exposed by its image, so it must be used to convert
+// the container into a service.
+func (s *serviceFunc) toContainer(ctx context.Context, state object.State, input object.InputArgs) (*dagger.Container, *serviceFunc, error) {
 	fmt.Printf("Invoking service %s\n", s.service.Name())
 
+	for _, warning := range s.service.Warnings() {
+		fmt.Printf("warning: service %s: %s\n", s.service.Name(), warning)
+	}
+
 	// Loads the Dagger object instance from the object state
 	compose, err := s.c.load(state)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load object state: %w", err)
+		return nil, nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	if err := compose.loadFiles(ctx); err != nil {
+		return nil, nil, err
 	}
 
+	// The service may be overridden by the extra compose files, so its
+	// configuration is retrieved from the merged project.
+	baseService := s.service
+	service, err := compose.dockercompose.GetService(s.service.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	s = &serviceFunc{c: compose, service: service, asDep: s.asDep}
+
 	envMap, secretsMap := s.service.Environment()
-	mountedSecretsName := s.service.MountedSecrets()
+	mountedSecretRefs := s.service.MountedSecrets()
 	mountedVolumePaths, cachesPaths := s.service.Volumes()
 
 	// The image may be overwritten by the user
+	//
+	// The argument defaults to the image of the base files, so it's only used
+	// if it differs from it or if the extra files don't override it.
 	source := s.service.Source()
 	if source.Type == dockercompose.SourceTypeImage {
-		source.Image.Ref = utils.LoadArgument[string](s.formatInputArgName("image"), input)
+		image := utils.LoadArgument[string](s.formatInputArgName("image"), input)
+		if baseSource := baseService.Source(); image != "" && (baseSource.Type != dockercompose.SourceTypeImage || image != baseSource.Image.Ref) {
+			source.Image.Ref = image
+		}
 	}
 
 	// Loads the environment variables
+	//
+	// Variables added by the extra compose files have no argument so they
+	// keep their value.
 	env := map[string]string{}
-	for key := range envMap {
-		env[key] = utils.LoadArgument[string](s.formatInputArgName(utils.FormatEnvVariableName(key)), input)
+	for key, value := range envMap {
+		argName := s.formatInputArgName(utils.FormatEnvVariableName(key))
+		if input[argName] == nil && value != nil {
+			env[key] = *value
+
+			continue
+		}
+
+		env[key] = utils.LoadArgument[string](argName, input)
 	}
 
 	// Loads the secrets
@@ -233,7 +410,7 @@ func (s *serviceFunc) Invoke(ctx context.Context, state object.State, input obje
 
 			secretValue, err := cliSecret.Plaintext(ctx)
 			if err != nil {
-				return nil, fmt.Errorf("failed to add secret value: %w", err)
+				return nil, nil, fmt.Errorf("failed to add secret value: %w", err)
 			}
 
 			secrets[name] = dag.SetSecret(name, secretValue)
@@ -243,21 +420,33 @@ func (s *serviceFunc) Invoke(ctx context.Context, state object.State, input obje
 	}
 
 	// Load mounted secret arguments
-	//
-	// This workaround is required since the secret's name
-	// isn't the same as the identifier defined in the Dockerfile.
-	mountedSecrets := map[string]*dagger.Secret{}
-	for _, name := range mountedSecretsName {
-		if input[s.formatInputArgName(name)] != nil {
-			cliSecret := utils.LoadSecretFromID([]byte(input[s.formatInputArgName(name)]))
+	mountedSecrets := []*mountedSecret{}
+	for _, ref := range mountedSecretRefs {
+		secret, err := s.loadMountedSecret(ctx, ref, input)
+		if err != nil {
+			return nil, nil, err
+		}
 
-			secretValue, err := cliSecret.Plaintext(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("failed to add secret value: %w", err)
-			}
+		if secret == nil {
+			fmt.Printf("secret %s of service %s is not set, skipping it\n", ref.Name(), s.service.Name())
 
-			mountedSecrets[name] = dag.SetSecret(name, secretValue)
+			continue
 		}
+
+		mountedSecrets = append(mountedSecrets, &mountedSecret{ref: ref, secret: secret})
+	}
+
+	// Load config arguments
+	mountedConfigs := []*mountedConfig{}
+	for _, ref := range s.service.Configs() {
+		file := s.loadConfig(ref, input)
+		if file == nil {
+			fmt.Printf("config %s of service %s is not set, skipping it\n", ref.Name(), s.service.Name())
+
+			continue
+		}
+
+		mountedConfigs = append(mountedConfigs, &mountedConfig{ref: ref, file: file})
 	}
 
 	// Load mounted volume arguments
@@ -281,44 +470,64 @@ func (s *serviceFunc) Invoke(ctx context.Context, state object.State, input obje
 
 	// Add dependent services.
 	//
-	// If the dependent service is already running, bind it to the current service.
-	// Otherwise, create the dependent service.
+	// Each dependent service is started once per call graph, a service
+	// already started is reused, and the current service waits for its
+	// condition.
+	//
+	// Indirect dependencies are bound too so they are reachable like in a
+	// compose network, except one-shot services which aren't running.
+	conditions := map[string]dockercompose.DependencyCondition{}
+	for _, dependency := range s.service.Dependencies() {
+		conditions[dependency.Name()] = dependency.Condition()
+	}
+
 	dependentServices := []*proxy.Service{}
 	for _, dependentServiceName := range s.service.DependsOn() {
-		if compose.runningServices[dependentServiceName] != nil {
-			dependentService := compose.runningServices[dependentServiceName]
-			fmt.Printf("service %s is already running ; binding it to the dependent service %s\n", dependentServiceName, dependentService.Name, s.service.Name())
-
-			dependentServices = append(dependentServices, dependentService)
+		condition, direct := conditions[dependentServiceName]
+		if !direct {
+			if s.c.dockercompose.IsOneShot(dependentServiceName) {
+				continue
+			}
 
-			continue
+			condition = dockercompose.DependencyConditionStarted
 		}
 
-		fmt.Printf("service %s is not running yet; starting it\n", dependentServiceName)
+		// Unreachable dependencies are still started for their condition but
+		// they are not bound, like in separate compose networks.
+		reachable := s.canReach(dependentServiceName)
 
 		dockerComposeService, err := s.c.dockercompose.GetService(dependentServiceName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get service %s that %s depends on", dependentServiceName, s.service.Name())
+			return nil, nil, fmt.Errorf("failed to get service %s that %s depends on", dependentServiceName, s.service.Name())
 		}
 
 		serviceFct := &serviceFunc{c: compose, service: dockerComposeService, asDep: true}
 
-		service, err := serviceFct.ToService(ctx, state, input)
+		service, err := serviceFct.startAsDependency(ctx, state, input, condition)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get dependent service: %w", err)
+			return nil, nil, fmt.Errorf("failed to get dependent service: %w", err)
 		}
 
-		dependentServices = append(dependentServices, service)
+		if service != nil && reachable {
+			dependentServices = append(dependentServices, service)
+		}
 	}
 
 	fmt.Printf("Starting service %s\n", s.service.Name())
 
-	return (*serviceFunc).container(
-		&serviceFunc{c: compose, service: s.service, asDep: s.asDep}, ctx,
+	resolved := &serviceFunc{c: compose, service: s.service, asDep: s.asDep}
+
+	ctr, err := resolved.container(
+		ctx,
 		source, env, secrets,
-		mountedSecrets, volumes, mountedFiles,
+		mountedSecrets, mountedConfigs, volumes, mountedFiles,
 		caches, dependentServices,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ctr, resolved, nil
 }
 
 // Arguments returns the function arguments of this service.
@@ -372,15 +581,15 @@ func (s *serviceFunc) Arguments() []*object.FunctionArg {
 	}
 
 	// Add mounted secrets
-	mountedSecretsName := s.service.MountedSecrets()
-	for _, name := range mountedSecretsName {
-		args = append(args, &object.FunctionArg{
-			Name: name,
-			Type: dag.TypeDef().WithObject("Secret"),
-			Opts: dagger.FunctionWithArgOpts{
-				Description: fmt.Sprintf("Secret %s to mount", name),
-			},
-		})
+	for _, ref := range s.service.MountedSecrets() {
+		args = append(args, secretArgument(ref))
+	}
+
+	// Add configs
+	for _, ref := range s.service.Configs() {
+		if arg := configArgument(ref); arg != nil {
+			args = append(args, arg)
+		}
 	}
 
 	// Add mounted volumes
@@ -417,9 +626,14 @@ func (s *serviceFunc) Arguments() []*object.FunctionArg {
 //
 // It returns the updated module and object definition.
 func (s *serviceFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	description := fmt.Sprintf("Create a %s service container", s.service.Name())
+	if profiles := s.service.Profiles(); len(profiles) != 0 {
+		description = fmt.Sprintf("%s (profiles: %s)", description, strings.Join(profiles, ", "))
+	}
+
 	typedef := dag.
-		Function(s.service.Name(), dag.TypeDef().WithObject("Container")).
-		WithDescription(fmt.Sprintf("Create a %s service container", s.service.Name()))
+		Function(s.fnName, dag.TypeDef().WithObject("Container")).
+		WithDescription(description)
 
 	// Retrieve this service's arguments
 	args := s.Arguments()
@@ -429,8 +643,8 @@ func (s *serviceFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module
 
 	// Add dependent service arguments
 	for _, dependencyName := range s.service.DependsOn() {
-		service, exist := s.c.funcMap[dependencyName]
-		if !exist {
+		service := s.c.getServiceFunc(dependencyName)
+		if service == nil {
 			panic(fmt.Errorf("service %s does not exist but %s depends on it", dependencyName, s.service.Name()))
 		}
 
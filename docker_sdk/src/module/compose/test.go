@@ -0,0 +1,98 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// testFunc is a function that runs a test command in a service after
+// starting its dependencies and waiting for their conditions.
+//
+// The command's failure doesn't fail the function, it's reported in the
+// returned TestResult.
+type testFunc struct {
+	all *allFunc
+}
+
+// junit collects the JUnit reports at the given path of the container, it can
+// be a file or a directory.
+//
+// It returns nil if the path doesn't exist.
+func (t *testFunc) junit(ctx context.Context, ctr *dagger.Container, reportsPath string) *dagger.Directory {
+	dir := ctr.Directory(reportsPath)
+	if _, err := dir.Entries(ctx); err == nil {
+		return dir
+	}
+
+	file := ctr.File(reportsPath)
+	if _, err := file.Size(ctx); err == nil {
+		return dag.Directory().WithFile(path.Base(reportsPath), file)
+	}
+
+	fmt.Printf("no JUnit reports found at %s ; skipping them\n", reportsPath)
+
+	return nil
+}
+
+// Invoke executes the "Test" function with the given state and input arguments.
+func (t *testFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	ctr, err := runCommand(ctx, t.all, state, input, utils.LoadArgument[[]string]("command", input), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TestResult{}
+
+	result.ExitCode, err = ctr.ExitCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test exit code: %w", err)
+	}
+
+	result.Stdout, err = ctr.Stdout(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test stdout: %w", err)
+	}
+
+	result.Stderr, err = ctr.Stderr(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test stderr: %w", err)
+	}
+
+	if reportsPath := utils.LoadArgument[string]("junitPath", input); reportsPath != "" {
+		result.Junit = t.junit(ctx, ctr, reportsPath)
+	}
+
+	return result, nil
+}
+
+// Arguments returns the service, the test command, the JUnit reports path
+// and the arguments of every service.
+func (t *testFunc) Arguments() []*object.FunctionArg {
+	args := commandArguments(t.all, "command")
+
+	return append(args, &object.FunctionArg{
+		Name: "junitPath",
+		Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind).WithOptional(true),
+		Opts: dagger.FunctionWithArgOpts{
+			Description: "Path of the JUnit reports file or directory in the service's container",
+		},
+	})
+}
+
+// AddTypeDefToObject adds "Test" function definition to the given Dagger module's object.
+func (t *testFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Test", dag.TypeDef().WithObject("TestResult")).
+		WithDescription("Run a test command in a service after starting its dependencies and collect its result")
+
+	for _, arg := range t.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
@@ -0,0 +1,102 @@
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+)
+
+// TestResult is the result of a test command run by the "Test" function.
+//
+// Its fields are resolved by Dagger from the returned value, so it doesn't
+// have any function.
+type TestResult struct {
+	// ExitCode is the exit code of the test command.
+	ExitCode int
+
+	// Stdout is the standard output of the test command.
+	Stdout string
+
+	// Stderr is the standard error of the test command.
+	Stderr string
+
+	// Junit holds the JUnit reports collected after the test command, nil if
+	// no reports path is given or if it doesn't exist.
+	Junit *dagger.Directory
+}
+
+// Name returns the name of the object: "TestResult".
+func (t *TestResult) Name() string {
+	return "TestResult"
+}
+
+// Description provides a brief description of TestResult.
+func (t *TestResult) Description() string {
+	return "Result of a test command run against a compose stack"
+}
+
+// New creates a new empty TestResult.
+func (t *TestResult) New(input object.InputArgs) object.Object {
+	return &TestResult{}
+}
+
+// AddTypeDef adds the module type definition for this object with all its
+// fields.
+func (t *TestResult) AddTypeDef(ctx context.Context) dagger.WithModuleFunc {
+	return func(mod *dagger.Module) *dagger.Module {
+		object := dag.TypeDef().
+			WithObject(t.Name()).
+			WithField("ExitCode", dag.TypeDef().WithKind(dagger.TypeDefKindIntegerKind), dagger.TypeDefWithFieldOpts{
+				Description: "Exit code of the test command",
+			}).
+			WithField("Stdout", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Standard output of the test command",
+			}).
+			WithField("Stderr", dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), dagger.TypeDefWithFieldOpts{
+				Description: "Standard error of the test command",
+			}).
+			WithField("Junit", dag.TypeDef().WithObject("Directory").WithOptional(true), dagger.TypeDefWithFieldOpts{
+				Description: "JUnit reports collected after the test command",
+			})
+
+		return mod.WithObject(object)
+	}
+}
+
+// Load constructs a new TestResult from a saved state.
+func (t *TestResult) Load(state object.State) (object.Object, error) {
+	parentMap := make(map[string]interface{})
+	err := json.Unmarshal(state, &parentMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parent object: %w", err)
+	}
+
+	result := &TestResult{}
+
+	if exitCode, ok := parentMap["ExitCode"].(float64); ok {
+		result.ExitCode = int(exitCode)
+	}
+
+	if stdout, ok := parentMap["Stdout"].(string); ok {
+		result.Stdout = stdout
+	}
+
+	if stderr, ok := parentMap["Stderr"].(string); ok {
+		result.Stderr = stderr
+	}
+
+	if junit, ok := parentMap["Junit"].(string); ok {
+		result.Junit = dag.LoadDirectoryFromID(dagger.DirectoryID(junit))
+	}
+
+	return result, nil
+}
+
+// Invoke returns an error since TestResult doesn't have any function.
+func (t *TestResult) Invoke(ctx context.Context, state object.State, fnName string, input object.InputArgs) (object.Result, error) {
+	return nil, fmt.Errorf("unknown function %s", fnName)
+}
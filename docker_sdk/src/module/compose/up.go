@@ -0,0 +1,93 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/module/proxy"
+)
+
+// upFunc is a function that starts all services enabled by the active
+// profiles and returns the proxy exposing them as a service, like
+// `docker compose up`.
+//
+// There's no "Stop" counterpart since each call runs in a new process: the
+// returned service is stopped by the caller or at the end of the session.
+//
+// It shares the arguments of the "All" function.
+type upFunc struct {
+	all *allFunc
+}
+
+// Invoke executes the "Up" function with the given state and input arguments.
+func (u *upFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	ctr, _, err := u.all.proxy(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctr.AsService(), nil
+}
+
+// Arguments returns the arguments of the "All" function.
+func (u *upFunc) Arguments() []*object.FunctionArg {
+	return u.all.Arguments()
+}
+
+// AddTypeDefToObject adds "Up" function definition to the given Dagger module's object.
+func (u *upFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Up", dag.TypeDef().WithObject("Service")).
+		WithDescription(fmt.Sprintf("Start all services enabled by the active profiles (%s) behind a proxy exposing their published ports", u.all.serviceNames()))
+
+	for _, arg := range u.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
+
+// upServicesFunc is a function that starts all services enabled by the
+// active profiles and returns one service per compose service.
+//
+// Each service exposes the published ports of its compose service through
+// its own proxy, so it can be tunneled on its own.
+type upServicesFunc struct {
+	all *allFunc
+}
+
+// Invoke executes the "UpServices" function with the given state and input
+// arguments.
+func (u *upServicesFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	services, err := u.all.services(ctx, state, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*dagger.Service{}
+	for _, service := range services {
+		result = append(result, u.all.up(proxyBackend(input), []*proxy.Service{service}).AsService())
+	}
+
+	return result, nil
+}
+
+// Arguments returns the arguments of the "All" function.
+func (u *upServicesFunc) Arguments() []*object.FunctionArg {
+	return u.all.Arguments()
+}
+
+// AddTypeDefToObject adds "UpServices" function definition to the given
+// Dagger module's object.
+func (u *upServicesFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, obj *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("UpServices", dag.TypeDef().WithListOf(dag.TypeDef().WithObject("Service"))).
+		WithDescription(fmt.Sprintf("Start all services enabled by the active profiles (%s) and return one service per compose service exposing its published ports", u.all.serviceNames()))
+
+	for _, arg := range u.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, obj.WithFunction(typedef)
+}
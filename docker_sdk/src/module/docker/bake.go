@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/bake"
+	"dagger.io/dockersdk/module/object"
+)
+
+// bakeFunc represents a function that returns a `Bake` Dagger object
+// instance.
+type bakeFunc struct {
+	d *Docker
+}
+
+// bake returns a new bake instance.
+func (b *bakeFunc) bake() (*bake.Bake, error) {
+	return bake.New(b.d.Dir, b.d.bakeFile)
+}
+
+// Invoke returns the Bake object.
+func (b *bakeFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	if b.d.bakeFile == nil {
+		return nil, fmt.Errorf("bake file not loaded")
+	}
+
+	docker, err := b.d.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	return (*bakeFunc).bake(&bakeFunc{d: docker})
+}
+
+// Arguments returns nil as no arguments are expected.
+//
+// This method should never be called for this function.
+func (b *bakeFunc) Arguments() []*object.FunctionArg {
+	return nil
+}
+
+// AddTypeDefToObject adds the Bake function definition to the module and object.
+func (b *bakeFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function("Bake", dag.TypeDef().WithObject("Bake")).
+		WithDescription(fmt.Sprintf("Build targets of %s", b.d.bakeFile.Filename()))
+
+	return mod, object.WithFunction(typedef)
+}
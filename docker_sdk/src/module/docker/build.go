@@ -3,17 +3,61 @@ package docker
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/dockerfile"
 	"dagger.io/dockersdk/module/object"
 	"dagger.io/dockersdk/utils"
 )
 
+// reservedArgNames are the arguments of the functions building a Dockerfile,
+// the build arguments and secrets of the Dockerfile cannot use them.
+var reservedArgNames = []string{
+	"dockerfile",
+	"target",
+	"platform",
+	"platforms",
+	"address",
+	"tags",
+	"registryUsername",
+	"registryPassword",
+}
+
 // buildFunc encapsulates Docker build methods.
 type buildFunc struct {
 	// d holds the Docker object.
 	d *Docker
+
+	// dockerfile is the Dockerfile to build.
+	dockerfile *dockerfile.Dockerfile
+}
+
+// buildInput holds the build options loaded from the input arguments.
+type buildInput struct {
+	// target is the stage to build, empty for the last stage.
+	target string
+
+	// dockerfile is the path to the Dockerfile relative to the build context.
+	dockerfile string
+
+	// buildArgs are the build arguments to pass to the build.
+	buildArgs []dagger.BuildArg
+
+	// secrets are the secrets to pass to the build.
+	secrets []*dagger.Secret
+}
+
+// name returns the name of the function, suffixed by the Dockerfile's name.
+func (b *buildFunc) name() string {
+	return "Build" + b.dockerfile.Name()
+}
+
+// stageEnumName returns the name of the enum listing the Dockerfile's stages.
+func (b *buildFunc) stageEnumName() string {
+	return fmt.Sprintf("%s%sStage", b.d.name, b.dockerfile.Name())
 }
 
 // build constructs a container from the given parameters.
@@ -21,14 +65,17 @@ type buildFunc struct {
 // It takes optional platform, target, Dockerfile path, build arguments, and
 // secrets.
 //
+// The build context is filtered by its `.dockerignore` file before building.
+//
 // Returns a built dagger.Container.
 func (b *buildFunc) build(
+	ctx context.Context,
 	platform *dagger.Platform,
 	target *string,
 	dockerfile *string,
 	buildArgs []dagger.BuildArg,
 	secrets []*dagger.Secret,
-) *dagger.Container {
+) (*dagger.Container, error) {
 	opts := dagger.DirectoryDockerBuildOpts{
 		BuildArgs: buildArgs,
 		Secrets:   secrets,
@@ -46,41 +93,60 @@ func (b *buildFunc) build(
 		opts.Dockerfile = *dockerfile
 	}
 
-	return b.d.Dir.DockerBuild(opts)
+	buildContext, err := utils.FilterBuildContext(ctx, b.d.Dir.Directory(b.dockerfile.Dir()), opts.Dockerfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter build context: %w", err)
+	}
+
+	return buildContext.DockerBuild(opts), nil
 }
 
-// Invoke executes the build process.
-//
-// It verifies the presence of a Dockerfile and loads necessary resources from the
-// object state.
-//
-// It accepts context, object state, and input arguments, then performs
-// the build using the specified options.
+// buildPlatforms builds one container per given platform with the same
+// build options.
 //
-// Returns the build result or an error.
-func (b *buildFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
-	if b.d.dockerfile == nil {
-		return nil, fmt.Errorf("Build function invoked before Dockerfile is set")
+// If no platform is given, a single container is built for the default
+// platform.
+func (b *buildFunc) buildPlatforms(ctx context.Context, platforms []dagger.Platform, opts *buildInput) ([]*dagger.Container, error) {
+	if len(platforms) == 0 {
+		ctr, err := b.build(ctx, nil, &opts.target, &opts.dockerfile, opts.buildArgs, opts.secrets)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*dagger.Container{ctr}, nil
 	}
 
-	// Loads Docker object from object state.
-	docker, err := b.d.load(state)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load object state: %w", err)
+	ctrs := []*dagger.Container{}
+	for _, platform := range platforms {
+		ctr, err := b.build(ctx, &platform, &opts.target, &opts.dockerfile, opts.buildArgs, opts.secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build platform %s: %w", platform, err)
+		}
+
+		ctrs = append(ctrs, ctr)
 	}
 
-	// Loads platform, dockerfile and target from input.
-	platform := utils.LoadArgument[dagger.Platform]("platform", input)
-	target := utils.LoadArgument[string]("target", input)
-	dockerfile := utils.LoadArgument[string]("dockerfile", input)
+	return ctrs, nil
+}
+
+// loadInput loads the build options from the input arguments.
+//
+// The input arguments are the ones returned by Arguments.
+func (b *buildFunc) loadInput(ctx context.Context, input object.InputArgs) (*buildInput, error) {
+	// Loads dockerfile and target from input.
+	opts := &buildInput{
+		target:     utils.LoadArgument[string]("target", input),
+		dockerfile: utils.LoadArgument[string]("dockerfile", input),
+		buildArgs:  []dagger.BuildArg{},
+		secrets:    []*dagger.Secret{},
+	}
 
 	// Loads build arguments from input.
-	buildArgs := []dagger.BuildArg{}
-	for key := range b.d.dockerfile.Args() {
-		if input[key] != nil {
-			buildArgs = append(buildArgs, dagger.BuildArg{
+	for key := range b.dockerfile.Args() {
+		if input[b.buildArgName(key)] != nil {
+			opts.buildArgs = append(opts.buildArgs, dagger.BuildArg{
 				Name:  key,
-				Value: utils.LoadArgument[string](key, input),
+				Value: utils.LoadArgument[string](b.buildArgName(key), input),
 			})
 		}
 	}
@@ -89,50 +155,82 @@ func (b *buildFunc) Invoke(ctx context.Context, state object.State, input object
 	//
 	// This workaround is required since the secret's name
 	// isn't the same as the identifier defined in the Dockerfile.
-	secrets := []*dagger.Secret{}
-	for _, secretKey := range b.d.dockerfile.Secrets() {
-		if input[secretKey] != nil {
-			cliSecret := utils.LoadSecretFromID([]byte(input[secretKey]))
+	for _, secret := range b.dockerfile.Secrets() {
+		if input[b.secretArgName(secret)] != nil {
+			cliSecret := utils.LoadSecretFromID([]byte(input[b.secretArgName(secret)]))
 
 			secretValue, err := cliSecret.Plaintext(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to add secret value: %w", err)
 			}
 
-			secrets = append(secrets, dag.SetSecret(secretKey, secretValue))
+			opts.secrets = append(opts.secrets, dag.SetSecret(secret.ID, secretValue))
+		}
+	}
+
+	// The Docker build of the Dagger engine cannot forward SSH sockets yet,
+	// a build requiring one would fail without it.
+	for _, ssh := range b.dockerfile.SSH() {
+		if ssh.Required {
+			return nil, fmt.Errorf("ssh mounts are not supported by DockerBuild: %s requires the %s SSH socket", b.dockerfile.Path(), ssh.ID)
 		}
 	}
 
-	return (*buildFunc).build(&buildFunc{d: docker}, &platform, &target, &dockerfile, buildArgs, secrets), nil
+	return opts, nil
 }
 
-// Arguments is a placeholder method not invoked for this function
-// required to implements the object.Function interface.
+// Invoke executes the build process.
 //
-// This function should never be called for this function.
-func (b *buildFunc) Arguments() []*object.FunctionArg {
-	return nil
+// It verifies the presence of a Dockerfile and loads necessary resources from the
+// object state.
+//
+// It accepts context, object state, and input arguments, then performs
+// the build using the specified options.
+//
+// Returns the build result or an error.
+func (b *buildFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	if b.dockerfile == nil {
+		return nil, fmt.Errorf("%s function invoked before Dockerfile is set", b.name())
+	}
+
+	// Loads Docker object from object state.
+	docker, err := b.d.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	// Loads platform from input.
+	platform := utils.LoadArgument[dagger.Platform]("platform", input)
+
+	opts, err := b.loadInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*buildFunc).build(&buildFunc{d: docker, dockerfile: b.dockerfile}, ctx, &platform, &opts.target, &opts.dockerfile, opts.buildArgs, opts.secrets)
 }
 
-// AddTypeDefToObject adds the "Build" function definition to a Dagger
-// module's object.
+// Arguments returns the build options arguments of the Dockerfile.
 //
-// It defines the function signature including Dockerfile path, build arguments,
-// secrets, platform, and target stages.
+// It includes the Dockerfile path, build arguments, secrets and target
+// stage but not the platform, so functions building the Dockerfile
+// can share them.
 //
-// It returns the updated module and type definition.
-func (b *buildFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
-	typedef := dag.Function("Build", dag.TypeDef().WithObject("Container")).
-		WithDescription("Build a container from the Dockerfile in the current directory").
-		WithArg("dockerfile",
-			dag.TypeDef().WithKind(dagger.TypeDefKindStringKind).WithOptional(true),
-			dagger.FunctionWithArgOpts{
-				DefaultValue: utils.LoadDefaultValue(b.d.dockerfile.Filename()),
+// The target stage's enum is registered by AddTypeDefToObject.
+func (b *buildFunc) Arguments() []*object.FunctionArg {
+	args := []*object.FunctionArg{
+		{
+			Name: "dockerfile",
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				DefaultValue: utils.LoadDefaultValue(b.dockerfile.Filename()),
 				Description:  "Path to the Dockerfile to use.",
-			})
+			},
+		},
+	}
 
 	// Add the build arguments
-	for key, value := range b.d.dockerfile.Args() {
+	for key, value := range b.dockerfile.Args() {
 		buildArgOpts := dagger.FunctionWithArgOpts{
 			Description: fmt.Sprintf("Set %s build argument", key),
 		}
@@ -141,16 +239,56 @@ func (b *buildFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module,
 			buildArgOpts.DefaultValue = utils.LoadDefaultValue(value)
 		}
 
-		typedef = typedef.WithArg(key, dag.TypeDef().WithKind(dagger.TypeDefKindStringKind), buildArgOpts)
+		args = append(args, &object.FunctionArg{
+			Name: b.buildArgName(key),
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind),
+			Opts: buildArgOpts,
+		})
 	}
 
 	// Add the secrets arguments
-	for _, secret := range b.d.dockerfile.Secrets() {
-		typedef = typedef.WithArg(secret,
-			dag.TypeDef().WithObject("Secret"),
-			dagger.FunctionWithArgOpts{
-				Description: fmt.Sprintf("Set %s secret", secret),
-			})
+	//
+	// Like in Docker, a secret is optional unless it's mounted with
+	// `required=true`.
+	for _, secret := range b.dockerfile.Secrets() {
+		description := fmt.Sprintf("Set %s secret", secret.ID)
+
+		args = append(args, &object.FunctionArg{
+			Name: b.secretArgName(secret),
+			Type: dag.TypeDef().WithObject("Secret").WithOptional(!secret.Required),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: description,
+			},
+		})
+	}
+
+	// Add target stage option if stages are declared in the Dockerfile.
+	if len(b.dockerfile.Stages()) != 0 {
+		args = append(args, &object.FunctionArg{
+			Name: "target",
+			Type: dag.TypeDef().WithEnum(b.stageEnumName()).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Target stage to build.",
+			},
+		})
+	}
+
+	return args
+}
+
+// AddTypeDefToObject adds the "Build" function definition of the Dockerfile
+// to a Dagger module's object.
+//
+// It defines the function signature including Dockerfile path, build arguments,
+// secrets, platform, and target stages.
+//
+// It returns the updated module and type definition.
+func (b *buildFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function(b.name(), dag.TypeDef().WithObject("Container")).
+		WithDescription(fmt.Sprintf("Build a container from %s%s", b.dockerfile.Path(), b.mountsLimitation()))
+
+	for _, arg := range b.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
 	}
 
 	// Add the platform argument
@@ -171,24 +309,86 @@ func (b *buildFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module,
 			defaultPlatformArgOpts,
 		)
 
-	// Add target stage option if stages are declared in the Dockerfile.
-	if len(b.d.dockerfile.Stages()) != 0 {
-		stageTypeDef := dag.TypeDef().WithEnum(fmt.Sprintf("%sStage", b.d.name))
+	// Register the target stage enum if stages are declared in the Dockerfile.
+	if len(b.dockerfile.Stages()) != 0 {
+		stageTypeDef := dag.TypeDef().WithEnum(b.stageEnumName())
 
-		for _, stage := range b.d.dockerfile.Stages() {
+		for _, stage := range b.dockerfile.Stages() {
 			stageTypeDef = stageTypeDef.WithEnumValue(stage)
 		}
 
-		typedef = typedef.
-			WithArg("target", dag.TypeDef().WithEnum(fmt.Sprintf("%sStage", b.d.name)).
-				WithOptional(true),
-				dagger.FunctionWithArgOpts{
-					Description: "Target stage to build.",
-				},
-			)
-
 		mod = mod.WithEnum(stageTypeDef)
 	}
 
 	return mod, object.WithFunction(typedef)
 }
+
+// platformsArgument returns the argument listing the platforms to build.
+func platformsArgument() *object.FunctionArg {
+	return &object.FunctionArg{
+		Name: "platforms",
+		Type: dag.TypeDef().WithListOf(dag.TypeDef().WithScalar("Platform")).WithOptional(true),
+		Opts: dagger.FunctionWithArgOpts{
+			Description: "Platforms to build, default to the engine's platform.",
+		},
+	}
+}
+
+// buildArgName returns the name of the argument of the given build argument.
+//
+// It's prefixed by "buildArg" if it clashes with a reserved argument once
+// converted to camel case by Dagger (e.g., `ARG TARGET` is "buildArgTarget").
+func (b *buildFunc) buildArgName(key string) string {
+	if slices.Contains(normalizedArgNames(reservedArgNames), normalizeArgName(key)) {
+		return "buildArg" + utils.FormatName(strings.ToLower(key))
+	}
+
+	return key
+}
+
+// secretArgName returns the name of the argument of the given secret.
+//
+// It's prefixed by "secret" if it clashes with a reserved argument or a
+// build argument (e.g., a `NPM_TOKEN` secret next to `ARG NPM_TOKEN` is
+// "secretNpmToken").
+func (b *buildFunc) secretArgName(secret *dockerfile.Secret) string {
+	names := normalizedArgNames(reservedArgNames)
+	for key := range b.dockerfile.Args() {
+		names = append(names, normalizeArgName(key))
+	}
+
+	if slices.Contains(names, normalizeArgName(secret.ID)) {
+		return "secret" + utils.FormatName(strings.ToLower(secret.ID))
+	}
+
+	return secret.ID
+}
+
+// normalizeArgName returns the given argument name the way Dagger compares
+// them: case insensitive and without separators.
+func normalizeArgName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(name))
+}
+
+// normalizedArgNames returns the normalized names of the given arguments.
+func normalizedArgNames(names []string) []string {
+	normalized := []string{}
+	for _, name := range names {
+		normalized = append(normalized, normalizeArgName(name))
+	}
+
+	return normalized
+}
+
+// mountsLimitation returns a note on the SSH mounts of the Dockerfile for the
+// descriptions of the build functions, empty if it has none.
+//
+// The Docker build of the Dagger engine cannot forward SSH sockets, so no
+// socket argument can be exposed.
+func (b *buildFunc) mountsLimitation() string {
+	if len(b.dockerfile.SSH()) == 0 {
+		return ""
+	}
+
+	return " (SSH mounts are not supported: optional sockets are skipped, required ones fail the build)"
+}
@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// buildAllFunc builds a Dockerfile for several platforms at once.
+type buildAllFunc struct {
+	// build is the build function of the Dockerfile to build.
+	build *buildFunc
+}
+
+// name returns the name of the function, suffixed by the Dockerfile's name.
+func (b *buildAllFunc) name() string {
+	return "BuildAll" + b.build.dockerfile.Name()
+}
+
+// buildAll builds the containers of all platforms concurrently.
+//
+// Returns the built containers, in the same order as the platforms.
+func (b *buildAllFunc) buildAll(ctx context.Context, platforms []dagger.Platform, opts *buildInput) ([]*dagger.Container, error) {
+	ctrs, err := b.build.buildPlatforms(ctx, platforms, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, ctr := range ctrs {
+		eg.Go(func() error {
+			synced, err := ctr.Sync(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to build container %d: %w", i, err)
+			}
+
+			ctrs[i] = synced
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return ctrs, nil
+}
+
+// Invoke builds the Dockerfile for every requested platform.
+func (b *buildAllFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	docker, err := b.build.d.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	build := &buildFunc{d: docker, dockerfile: b.build.dockerfile}
+
+	opts, err := build.loadInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := utils.LoadArgument[[]dagger.Platform]("platforms", input)
+
+	return (*buildAllFunc).buildAll(&buildAllFunc{build: build}, ctx, platforms, opts)
+}
+
+// Arguments returns the arguments of the build all function.
+//
+// These are the build arguments plus the platforms argument.
+func (b *buildAllFunc) Arguments() []*object.FunctionArg {
+	return append([]*object.FunctionArg{platformsArgument()}, b.build.Arguments()...)
+}
+
+// AddTypeDefToObject adds the "BuildAll" function definition of the
+// Dockerfile to a Dagger module's object.
+func (b *buildAllFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function(b.name(), dag.TypeDef().WithListOf(dag.TypeDef().WithObject("Container"))).
+		WithDescription(fmt.Sprintf("Build a container from %s for each platform%s", b.build.dockerfile.Path(), b.build.mountsLimitation()))
+
+	for _, arg := range b.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, object.WithFunction(typedef)
+}
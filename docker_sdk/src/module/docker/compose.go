@@ -3,11 +3,13 @@ package docker
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
 	"dagger.io/dockersdk/module/compose"
 	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
 )
 
 // composeFunc represents a function that returns a `Compose` Dagger object
@@ -16,9 +18,17 @@ type composeFunc struct {
 	d *Docker
 }
 
-// compose returns a new compose instance.
-func (c *composeFunc) compose() *compose.Compose {
-	return compose.New(c.d.Dir, c.d.dockercomposeFile)
+// compose returns a new compose instance with the given active profiles and
+// extra compose files.
+func (c *composeFunc) compose(profiles []string, files []string) (*compose.Compose, error) {
+	composeObj, err := compose.New(c.d.Dir, c.d.dockercomposeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeObj.
+		WithProfiles(profiles).
+		WithFiles(files), nil
 }
 
 // Invoke executes the docker compose operation.
@@ -32,14 +42,40 @@ func (c *composeFunc) Invoke(ctx context.Context, state object.State, input obje
 		return nil, fmt.Errorf("failed to object state: %w", err)
 	}
 
-	return (*composeFunc).compose(&composeFunc{d: docker}), nil
+	profiles := utils.LoadArgument[[]string]("profiles", input)
+	files := utils.LoadArgument[[]string]("files", input)
+
+	composeObj, err := (*composeFunc).compose(&composeFunc{d: docker}, profiles, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeObj, nil
 }
 
-// Arguments returns nil as no arguments are expected.
-//
-// This method should never be called for this function.
+// Arguments returns the profiles and extra compose files arguments.
 func (c *composeFunc) Arguments() []*object.FunctionArg {
-	return nil
+	description := "Profiles to enable."
+	if profiles := c.d.dockercomposeFile.Profiles(); len(profiles) != 0 {
+		description = fmt.Sprintf("Profiles to enable (%s), \"*\" enables all of them.", strings.Join(profiles, ", "))
+	}
+
+	return []*object.FunctionArg{
+		{
+			Name: "profiles",
+			Type: dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: description,
+			},
+		},
+		{
+			Name: "files",
+			Type: dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: fmt.Sprintf("Extra compose files to merge on top of %s.", strings.Join(c.d.dockercomposeFile.Filenames(), ", ")),
+			},
+		},
+	}
 }
 
 // AddTypeDefToObject adds the Compose function definition to the module and object.
@@ -47,5 +83,9 @@ func (c *composeFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module
 	typedef := dag.Function("Compose", dag.TypeDef().WithObject("Compose")).
 		WithDescription("Manage docker compose services")
 
+	for _, arg := range c.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
 	return mod, object.WithFunction(typedef)
 }
\ No newline at end of file
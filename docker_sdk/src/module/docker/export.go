@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// exportFunc builds a Dockerfile and exports the result as an OCI tarball.
+type exportFunc struct {
+	// build is the build function of the Dockerfile to export.
+	build *buildFunc
+}
+
+// name returns the name of the function, suffixed by the Dockerfile's name.
+func (e *exportFunc) name() string {
+	return "Export" + e.build.dockerfile.Name()
+}
+
+// Invoke builds the Dockerfile for every requested platform and returns
+// them as a single OCI tarball.
+func (e *exportFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	docker, err := e.build.d.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	build := &buildFunc{d: docker, dockerfile: e.build.dockerfile}
+
+	opts, err := build.loadInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := utils.LoadArgument[[]dagger.Platform]("platforms", input)
+
+	ctrs, err := build.buildPlatforms(ctx, platforms, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctrs[0].AsTarball(dagger.ContainerAsTarballOpts{
+		PlatformVariants: ctrs[1:],
+	}), nil
+}
+
+// Arguments returns the arguments of the export function.
+//
+// These are the build arguments plus the platforms argument.
+func (e *exportFunc) Arguments() []*object.FunctionArg {
+	return append([]*object.FunctionArg{platformsArgument()}, e.build.Arguments()...)
+}
+
+// AddTypeDefToObject adds the "Export" function definition of the
+// Dockerfile to a Dagger module's object.
+func (e *exportFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function(e.name(), dag.TypeDef().WithObject("File")).
+		WithDescription(fmt.Sprintf("Build %s and export it as an OCI tarball%s", e.build.dockerfile.Path(), e.build.mountsLimitation()))
+
+	for _, arg := range e.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, object.WithFunction(typedef)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/codebase/bake"
 	"dagger.io/dockersdk/codebase/dockercompose"
 	"dagger.io/dockersdk/codebase/dockerfile"
+	bakeobj "dagger.io/dockersdk/module/bake"
 	"dagger.io/dockersdk/module/compose"
 	"dagger.io/dockersdk/module/object"
 	"dagger.io/dockersdk/utils"
@@ -21,12 +24,15 @@ type Docker struct {
 	// name is the identifier for the Docker object.
 	name string
 
-	// dockerfile represents the Dockerfile associated with this Docker object.
-	dockerfile *dockerfile.Dockerfile
+	// dockerfiles represents the Dockerfiles associated with this Docker object.
+	dockerfiles []*dockerfile.Dockerfile
 
 	// dockercomposeFile represents the Docker Compose file linked with this Docker object.
 	dockercomposeFile *dockercompose.DockerCompose
 
+	// bakeFile represents the bake file linked with this Docker object.
+	bakeFile *bake.Bake
+
 	// funcMap is a map of function names to their corresponding implementation.
 	funcMap map[string]object.Function
 }
@@ -34,9 +40,8 @@ type Docker struct {
 // New creates a new Docker object with the specified name.
 func New(name string) *Docker {
 	return &Docker{
-		name:       name,
-		dockerfile: &dockerfile.Dockerfile{},
-		funcMap:    map[string]object.Function{},
+		name:    name,
+		funcMap: map[string]object.Function{},
 	}
 }
 
@@ -91,8 +96,9 @@ func (d *Docker) load(state object.State) (*Docker, error) {
 
 	cpyDocker := &Docker{
 		name:              d.name,
-		dockerfile:        d.dockerfile,
+		dockerfiles:       d.dockerfiles,
 		dockercomposeFile: d.dockercomposeFile,
+		bakeFile:          d.bakeFile,
 		funcMap:           d.funcMap,
 	}
 
@@ -113,10 +119,22 @@ func (d *Docker) Invoke(ctx context.Context, state object.State, fnName string,
 }
 
 // WithDockerfile associates a Dockerfile with the Docker object and adds
-// a "Build" function.
+// "Build", "BuildAll", "Publish" and "Export" functions suffixed by the
+// Dockerfile's name.
 func (d *Docker) WithDockerfile(dockerfile *dockerfile.Dockerfile) *Docker {
-	d.dockerfile = dockerfile
-	d.funcMap["Build"] = &buildFunc{d: d}
+	d.dockerfiles = append(d.dockerfiles, dockerfile)
+
+	build := &buildFunc{d: d, dockerfile: dockerfile}
+	d.funcMap[build.name()] = build
+
+	buildAll := &buildAllFunc{build: build}
+	d.funcMap[buildAll.name()] = buildAll
+
+	publish := &publishFunc{build: build}
+	d.funcMap[publish.name()] = publish
+
+	export := &exportFunc{build: build}
+	d.funcMap[export.name()] = export
 
 	return d
 }
@@ -130,15 +148,39 @@ func (d *Docker) WithDockerCompose(dockercomposeFile *dockercompose.DockerCompos
 	return d
 }
 
+// WithBake associates a bake file with the Docker object and adds a "Bake"
+// function.
+func (d *Docker) WithBake(bakeFile *bake.Bake) *Docker {
+	d.bakeFile = bakeFile
+	d.funcMap["Bake"] = &bakeFunc{d: d}
+
+	return d
+}
+
 // Deps returns a map of dependent objects for the Docker object.
-func (d *Docker) Deps() map[string]object.Object {
+//
+// Returns an error if an object cannot expose its functions.
+func (d *Docker) Deps() (map[string]object.Object, error) {
 	deps := make(map[string]object.Object)
 
 	if d.dockercomposeFile != nil {
-		composeObj := compose.New(d.Dir, d.dockercomposeFile)
+		composeObj, err := compose.New(d.Dir, d.dockercomposeFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", strings.Join(d.dockercomposeFile.Filenames(), ", "), err)
+		}
 
 		deps[composeObj.Name()] = composeObj
+		deps = utils.MergeObjectsMap(deps, composeObj.Deps())
+	}
+
+	if d.bakeFile != nil {
+		bakeObj, err := bakeobj.New(d.Dir, d.bakeFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", d.bakeFile.Filename(), err)
+		}
+
+		deps[bakeObj.Name()] = bakeObj
 	}
 
-	return deps
+	return deps, nil
 }
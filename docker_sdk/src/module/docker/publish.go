@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"dagger.io/dockersdk/module/object"
+	"dagger.io/dockersdk/utils"
+)
+
+// publishFunc builds a Dockerfile and publishes the result to a registry.
+type publishFunc struct {
+	// build is the build function of the Dockerfile to publish.
+	build *buildFunc
+}
+
+// name returns the name of the function, suffixed by the Dockerfile's name.
+func (p *publishFunc) name() string {
+	return "Publish" + p.build.dockerfile.Name()
+}
+
+// publish pushes the given containers to the address for each tag.
+//
+// All containers are published as platform variants of a single manifest
+// list.
+// If no tag is given, the address is used as is, otherwise the tag or digest
+// of the address is replaced by each tag.
+//
+// Returns the fully qualified references of the published images.
+func (p *publishFunc) publish(
+	ctx context.Context,
+	ctrs []*dagger.Container,
+	address string,
+	tags []string,
+) ([]string, error) {
+	refs := []string{address}
+	if len(tags) != 0 {
+		refs = []string{}
+		for _, tag := range tags {
+			refs = append(refs, fmt.Sprintf("%s:%s", repository(address), tag))
+		}
+	}
+
+	published := []string{}
+	for _, ref := range refs {
+		publishedRef, err := ctrs[0].Publish(ctx, ref, dagger.ContainerPublishOpts{
+			PlatformVariants: ctrs[1:],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish %s: %w", ref, err)
+		}
+
+		published = append(published, publishedRef)
+	}
+
+	return published, nil
+}
+
+// Invoke builds the Dockerfile for every requested platform and publishes
+// the result.
+func (p *publishFunc) Invoke(ctx context.Context, state object.State, input object.InputArgs) (object.Result, error) {
+	docker, err := p.build.d.load(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load object state: %w", err)
+	}
+
+	build := &buildFunc{d: docker, dockerfile: p.build.dockerfile}
+
+	opts, err := build.loadInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := utils.LoadArgument[[]dagger.Platform]("platforms", input)
+
+	ctrs, err := build.buildPlatforms(ctx, platforms, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	address := utils.LoadArgument[string]("address", input)
+	username := utils.LoadArgument[string]("registryUsername", input)
+
+	if username != "" && input["registryPassword"] != nil {
+		password := utils.LoadSecretFromID([]byte(input["registryPassword"]))
+
+		for i, ctr := range ctrs {
+			ctrs[i] = ctr.WithRegistryAuth(address, username, password)
+		}
+	}
+
+	return (*publishFunc).publish(
+		&publishFunc{build: build},
+		ctx, ctrs, address,
+		utils.LoadArgument[[]string]("tags", input),
+	)
+}
+
+// Arguments returns the arguments of the publish function.
+//
+// These are the build arguments plus the registry and platforms arguments.
+func (p *publishFunc) Arguments() []*object.FunctionArg {
+	args := []*object.FunctionArg{
+		{
+			Name: "address",
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Registry address to publish the image to (e.g., docker.io/user/app).",
+			},
+		},
+		{
+			Name: "registryUsername",
+			Type: dag.TypeDef().WithKind(dagger.TypeDefKindStringKind).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Username to authenticate to the registry.",
+			},
+		},
+		{
+			Name: "registryPassword",
+			Type: dag.TypeDef().WithObject("Secret").WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Password to authenticate to the registry.",
+			},
+		},
+		{
+			Name: "tags",
+			Type: dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind)).WithOptional(true),
+			Opts: dagger.FunctionWithArgOpts{
+				Description: "Tags to publish, replacing the tag of the address, the address is used as is if not set.",
+			},
+		},
+		platformsArgument(),
+	}
+
+	return append(args, p.build.Arguments()...)
+}
+
+// AddTypeDefToObject adds the "Publish" function definition of the
+// Dockerfile to a Dagger module's object.
+func (p *publishFunc) AddTypeDefToObject(ctx context.Context, mod *dagger.Module, object *dagger.TypeDef) (*dagger.Module, *dagger.TypeDef) {
+	typedef := dag.Function(p.name(), dag.TypeDef().WithListOf(dag.TypeDef().WithKind(dagger.TypeDefKindStringKind))).
+		WithDescription(fmt.Sprintf("Build %s and publish it to a registry, returns the published references%s", p.build.dockerfile.Path(), p.build.mountsLimitation()))
+
+	for _, arg := range p.Arguments() {
+		typedef = typedef.WithArg(arg.Name, arg.Type, arg.Opts)
+	}
+
+	return mod, object.WithFunction(typedef)
+}
+
+// repository returns the given image address without its tag nor digest,
+// e.g., "localhost:5000/app:1.0" is "localhost:5000/app".
+func repository(address string) string {
+	name, _, _ := strings.Cut(address, "@")
+
+	// A colon before the last slash separates the registry from its port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i]
+	}
+
+	return name
+}
@@ -26,13 +26,18 @@ type Module struct {
 }
 
 // Build initializes a new Module with a given name and Docker configuration.
-func Build(name string, docker *docker.Docker) *Module {
+func Build(name string, docker *docker.Docker) (*Module, error) {
 	baseObjects := map[string]object.Object{
 		// The default object for the docker SDK
 		"Docker": docker,
 	}
 
-	objects := utils.MergeObjectsMap(baseObjects, docker.Deps())
+	deps, err := docker.Deps()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := utils.MergeObjectsMap(baseObjects, deps)
 
 	return &Module{
 		name: name,
@@ -40,7 +45,7 @@ func Build(name string, docker *docker.Docker) *Module {
 			"Docker": &dockerFunc{d: docker},
 		},
 		objects: objects,
-	}
+	}, nil
 }
 
 // Name returns the name of the module.
@@ -0,0 +1,37 @@
+package proxy
+
+import (
+	"fmt"
+
+	"dagger.io/dagger"
+)
+
+// Backend exposes the ports of services from a single container.
+type Backend interface {
+	// WithService adds the given service to the backend.
+	WithService(service *Service) Backend
+
+	// Service returns the configured container ready to start services.
+	Service() *dagger.Container
+}
+
+// frontends maps the ports already exposed by a backend to the name of the
+// service they are forwarded to.
+type frontends map[string]string
+
+// reserve reserves the frontend port for the given service.
+//
+// It returns false with a warning if the port is already used by another
+// service.
+func (f frontends) reserve(port *Port, service string) bool {
+	frontend := fmt.Sprintf("%d/%s", port.Frontend, port.Protocol)
+	if owner, exist := f[frontend]; exist {
+		fmt.Printf("warning: port %s of service %s is already used by service %s, ignoring it\n", frontend, service, owner)
+
+		return false
+	}
+
+	f[frontend] = service
+
+	return true
+}
@@ -32,6 +32,11 @@ http {
 
     #gzip  on;
 
+    map $http_upgrade $connection_upgrade {
+        default upgrade;
+        ''      close;
+    }
+
     include /etc/nginx/conf.d/*.conf;
 }
 `
\ No newline at end of file
@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+)
+
+// forwarderSource is the source of the forwarder binary.
+//
+//go:embed forwarder/main.go
+var forwarderSource string
+
+// Forwarder is a Backend forwarding TCP and UDP ports with a native Go
+// binary built from the SDK source.
+//
+// Unlike the Nginx proxy, it doesn't pull any image besides the Go image
+// already used to build the SDK, and HTTP ports are forwarded as TCP streams.
+type Forwarder struct {
+	// ctr is the internal container running the forwarder.
+	ctr *dagger.Container
+
+	// forwards are the forwards passed to the forwarder, formatted as
+	// `<protocol>:<port>:<host>:<port>`.
+	forwards []string
+
+	// frontends holds the ports already exposed by the forwarder.
+	frontends frontends
+}
+
+// NewForwarder builds the forwarder binary and initializes a Forwarder
+// without any service.
+func NewForwarder() *Forwarder {
+	binary := dag.Container().
+		From("golang:1.23.2-alpine").
+		WithWorkdir("/src").
+		WithNewFile("/src/main.go", forwarderSource).
+		WithEnvVariable("CGO_ENABLED", "0").
+		WithExec([]string{"go", "build", "-o", "/src/forwarder", "main.go"}).
+		File("/src/forwarder")
+
+	return &Forwarder{
+		ctr:       dag.Container().WithFile("/forwarder", binary),
+		frontends: frontends{},
+	}
+}
+
+// WithService adds the given service to the forwarder.
+//
+// Each port of the service is exposed by the forwarder and forwarded to its
+// backend port.
+// If the service isn't exposed, it will not be added to the forwarder.
+// A port already exposed for another service is skipped with a warning.
+func (f *Forwarder) WithService(
+	service *Service,
+) Backend {
+	if !service.Exposed {
+		return f
+	}
+
+	f.ctr = f.ctr.WithServiceBinding(service.Name, service.Service)
+
+	for _, port := range service.Ports {
+		if !f.frontends.reserve(port, service.Name) {
+			continue
+		}
+
+		f.forwards = append(f.forwards, fmt.Sprintf("%s:%d:%s:%d", strings.ToLower(string(port.Protocol)), port.Frontend, service.Name, port.Backend))
+
+		f.ctr = f.ctr.WithExposedPort(port.Frontend, dagger.ContainerWithExposedPortOpts{
+			Protocol: port.Protocol,
+		})
+	}
+
+	return f
+}
+
+// Service returns the configured forwarder container ready to start services.
+func (f *Forwarder) Service() *dagger.Container {
+	return f.ctr.WithDefaultArgs(append([]string{"/forwarder"}, f.forwards...))
+}
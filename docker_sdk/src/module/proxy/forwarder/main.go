@@ -0,0 +1,267 @@
+// Forwarder forwards TCP and UDP ports to other hosts.
+//
+// Usage:
+//
+//	forwarder <protocol>:<port>:<host>:<port>...
+//
+// Each argument is a forward formatted as `<protocol>:<port>:<host>:<port>`,
+// e.g., `tcp:8080:web:80` forwards the local TCP port 8080 to the port 80 of
+// the host web.
+//
+// It only depends on the standard library so it can be built from this
+// single file.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// udpIdleTimeout is the duration after which a UDP session without response
+// from its backend is closed.
+const udpIdleTimeout = 2 * time.Minute
+
+// udpBufferSize is the maximum size of a UDP datagram.
+const udpBufferSize = 65535
+
+// forward represents a local port forwarded to a backend.
+type forward struct {
+	// protocol is the transport protocol of the port, tcp or udp.
+	protocol string
+
+	// port is the local port to listen on.
+	port int
+
+	// backend is the address traffic is forwarded to.
+	backend string
+}
+
+// parseForward parses a forward formatted as `<protocol>:<port>:<host>:<port>`.
+func parseForward(spec string) (*forward, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid forward %q, expected <protocol>:<port>:<host>:<port>", spec)
+	}
+
+	protocol := strings.ToLower(parts[0])
+	if protocol != "tcp" && protocol != "udp" {
+		return nil, fmt.Errorf("invalid forward %q: unsupported protocol %s", spec, parts[0])
+	}
+
+	port, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward %q: %w", spec, err)
+	}
+
+	return &forward{
+		protocol: protocol,
+		port:     port,
+		backend:  net.JoinHostPort(parts[2], parts[3]),
+	}, nil
+}
+
+func main() {
+	forwards := []*forward{}
+	for _, spec := range os.Args[1:] {
+		f, err := parseForward(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		forwards = append(forwards, f)
+	}
+
+	// Keep running without any forward, like a proxy without services.
+	if len(forwards) == 0 {
+		log.Print("no port to forward")
+		select {}
+	}
+
+	errs := make(chan error)
+	for _, f := range forwards {
+		go func() {
+			errs <- f.serve()
+		}()
+	}
+
+	// A forward only returns if its listener fails, so the forwarder exits
+	// to report it.
+	log.Fatal(<-errs)
+}
+
+// serve listens on the local port and forwards its traffic to the backend.
+func (f *forward) serve() error {
+	log.Printf("forwarding %s port %d to %s", f.protocol, f.port, f.backend)
+
+	if f.protocol == "udp" {
+		return f.serveUdp()
+	}
+
+	return f.serveTcp()
+}
+
+// serveTcp forwards each TCP connection to a new connection to the backend.
+func (f *forward) serveTcp() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", f.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on tcp port %d: %w", f.port, err)
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return fmt.Errorf("failed to accept connection on tcp port %d: %w", f.port, err)
+		}
+
+		go f.handleTcp(conn)
+	}
+}
+
+// handleTcp copies the traffic between the connection and the backend until
+// both sides are closed.
+func (f *forward) handleTcp(conn net.Conn) {
+	defer conn.Close()
+
+	backend, err := net.Dial("tcp", f.backend)
+	if err != nil {
+		log.Printf("failed to connect to %s: %v", f.backend, err)
+
+		return
+	}
+	defer backend.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		io.Copy(backend, conn)
+		closeWrite(backend)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		io.Copy(conn, backend)
+		closeWrite(conn)
+	}()
+
+	wg.Wait()
+}
+
+// closeWrite signals the end of the stream to the other side of a TCP
+// connection while still reading its responses.
+func closeWrite(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.CloseWrite()
+	}
+}
+
+// udpSession is the connection to the backend of a UDP client.
+type udpSession struct {
+	// conn is the connection to the backend.
+	conn net.Conn
+
+	// active is the last time the client or the backend sent a datagram.
+	active time.Time
+}
+
+// udpSessions holds the sessions of the clients of a UDP port by address.
+//
+// The mutex is shared by the read loop and the reply goroutines so a session
+// is never used once closed.
+type udpSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*udpSession
+}
+
+// serveUdp forwards the datagrams of each client through its own connection
+// to the backend, so responses can be sent back to the right client.
+func (f *forward) serveUdp() error {
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{Port: f.port})
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp port %d: %w", f.port, err)
+	}
+
+	sessions := &udpSessions{sessions: map[string]*udpSession{}}
+
+	buf := make([]byte, udpBufferSize)
+	for {
+		n, client, err := listener.ReadFromUDP(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read on udp port %d: %w", f.port, err)
+		}
+
+		sessions.mu.Lock()
+
+		session, exist := sessions.sessions[client.String()]
+		if !exist {
+			conn, err := net.Dial("udp", f.backend)
+			if err != nil {
+				sessions.mu.Unlock()
+				log.Printf("failed to connect to %s: %v", f.backend, err)
+
+				continue
+			}
+
+			session = &udpSession{conn: conn}
+			sessions.sessions[client.String()] = session
+
+			go f.replyUdp(listener, client, session, sessions)
+		}
+
+		session.active = time.Now()
+
+		if _, err := session.conn.Write(buf[:n]); err != nil {
+			log.Printf("failed to forward datagram to %s: %v", f.backend, err)
+		}
+
+		sessions.mu.Unlock()
+	}
+}
+
+// replyUdp sends the backend responses back to the client until neither the
+// client nor the backend sent a datagram for udpIdleTimeout, then closes the
+// session.
+func (f *forward) replyUdp(listener *net.UDPConn, client *net.UDPAddr, session *udpSession, sessions *udpSessions) {
+	buf := make([]byte, udpBufferSize)
+	for {
+		session.conn.SetReadDeadline(time.Now().Add(udpIdleTimeout))
+
+		n, err := session.conn.Read(buf)
+		if err != nil {
+			sessions.mu.Lock()
+
+			// The client may still be sending datagrams without response.
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && time.Since(session.active) < udpIdleTimeout {
+				sessions.mu.Unlock()
+
+				continue
+			}
+
+			delete(sessions.sessions, client.String())
+			session.conn.Close()
+			sessions.mu.Unlock()
+
+			return
+		}
+
+		sessions.mu.Lock()
+		session.active = time.Now()
+		sessions.mu.Unlock()
+
+		if _, err := listener.WriteToUDP(buf[:n], client); err != nil {
+			log.Printf("failed to send datagram to %s: %v", client, err)
+		}
+	}
+}
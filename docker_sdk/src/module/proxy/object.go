@@ -3,6 +3,7 @@ package proxy
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"dagger.io/dagger"
@@ -17,55 +18,91 @@ type Service struct {
 	// Name is the unique name of the service.
 	Name string
 
-	// Alias is an alternative name for the service.
-	Alias string
+	// Aliases are the alternative hostnames of the service on all its
+	// networks, in addition to Name.
+	Aliases []string
 
+	// Ports are the ports of the service forwarded by the proxy.
+	Ports []*Port
+
+	// Exposed signals if the service should be exposed by the proxy.
+	Exposed bool
+}
+
+// Port represents a port forwarded by the proxy to a service.
+type Port struct {
 	// Frontend is the port number exposed by the proxy.
 	Frontend int
 
 	// Backend is the port number where the actual service is running.
 	Backend int
 
-	// IsTcp specifies whether the service uses the TCP protocol.
-	IsTcp bool
+	// Protocol is the transport protocol of the port.
+	Protocol dagger.NetworkProtocol
 
-	// Exposed signals if the service should be exposed by the proxy.
-	Exposed bool
+	// Http specifies whether the port is forwarded as HTTP instead of a
+	// stream, it's ignored for UDP ports.
+	Http bool
 }
 
 // Proxy is a struct that sets up a reverse proxy using an Nginx container.
 type Proxy struct {
 	// ctr is the internal container running the Nginx proxy.
 	ctr *dagger.Container
+
+	// frontends holds the ports already exposed by the proxy.
+	frontends frontends
 }
 
 // New initializes a Proxy with default Nginx configuration.
+//
+// The default server of the image is removed since it listens on the port
+// 80, which would shadow a service published on it.
 func New() *Proxy {
 	return &Proxy{
 		ctr: dag.Container().
 			From("nginx:1.25.3").
+			WithoutFile("/etc/nginx/conf.d/default.conf").
 			WithNewFile("/etc/nginx/stream.conf", streamConf).
 			WithNewFile("/etc/nginx/nginx.conf", nginxConf),
+		frontends: frontends{},
 	}
 }
 
 // WithService configures adds the given service to the proxy.
 //
+// Each port of the service is exposed by the proxy and forwarded to its
+// backend port.
 // If the service isn't exposed, it will not be added to the proxy.
+// A port already exposed for another service is skipped with a warning.
 func (p *Proxy) WithService(
 	service *Service,
-) *Proxy {
-	config := p.getConfig(service.Backend, service.Name, service.Frontend, service.IsTcp)
-	configPath := fmt.Sprintf("/etc/nginx/stream.d/%s.conf", service.Name)
-	if service.IsTcp {
-		configPath = fmt.Sprintf("/etc/nginx/conf.d/%s.conf", service.Name)
+) Backend {
+	if !service.Exposed {
+		return p
 	}
 
-	if service.Exposed {
+	p.ctr = p.ctr.WithServiceBinding(service.Name, service.Service)
+
+	for _, port := range service.Ports {
+		if !p.frontends.reserve(port, service.Name) {
+			continue
+		}
+
+		isUdp := port.Protocol == dagger.NetworkProtocolUdp
+		isHttp := port.Http && !isUdp
+
+		config := p.getConfig(port.Backend, service.Name, port.Frontend, isHttp, isUdp)
+		configPath := fmt.Sprintf("/etc/nginx/stream.d/%s-%d-%s.conf", service.Name, port.Frontend, strings.ToLower(string(port.Protocol)))
+		if isHttp {
+			configPath = fmt.Sprintf("/etc/nginx/conf.d/%s-%d.conf", service.Name, port.Frontend)
+		}
+
 		p.ctr = p.ctr.
 			WithNewFile(configPath, config).
-			WithServiceBinding(service.Name, service.Service).
-			WithExposedPort(service.Frontend)
+			WithExposedPort(port.Frontend, dagger.ContainerWithExposedPortOpts{
+				Protocol: port.Protocol,
+			})
 	}
 
 	return p
@@ -77,15 +114,18 @@ func (p *Proxy) Service() *dagger.Container {
 }
 
 // getConfig generates the Nginx configuration for a service.
-func (p *Proxy) getConfig(port int, name string, frontend int, isTcp bool) string {
+//
+// HTTP ports are forwarded by an HTTP server supporting websocket upgrades,
+// other ports are forwarded as TCP or UDP streams.
+func (p *Proxy) getConfig(port int, name string, frontend int, isHttp bool, isUdp bool) string {
 	var result bytes.Buffer
 	var config string
 
-	if !isTcp {
+	if !isHttp {
 		config = `
     server {
-      listen {{ .frontend }};
-      listen [::]:{{ .frontend }};
+      listen {{ .frontend }}{{ if .udp }} udp{{ end }};
+      listen [::]:{{ .frontend }}{{ if .udp }} udp{{ end }};
       proxy_pass {{ .name }}:{{ .port }};
     }
 `
@@ -99,6 +139,9 @@ func (p *Proxy) getConfig(port int, name string, frontend int, isTcp bool) strin
     
       location / {
         proxy_pass http://{{ .name }}:{{ .port }};
+        proxy_http_version 1.1;
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection $connection_upgrade;
         proxy_set_header Host $http_host;
         proxy_set_header X-Forwarded-Host $http_host;
         proxy_set_header X-Real-IP $remote_addr;
@@ -114,6 +157,7 @@ func (p *Proxy) getConfig(port int, name string, frontend int, isTcp bool) strin
 		"frontend": frontend,
 		"name":     name,
 		"port":     port,
+		"udp":      isUdp,
 	})
 
 	return result.String()
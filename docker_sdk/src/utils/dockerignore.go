@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dagger.io/dagger"
+	"dagger.io/dagger/dag"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// FilterBuildContext returns the build context without the files excluded by
+// its ignore file.
+//
+// Like Docker, it first looks for a `<Dockerfile>.dockerignore` file next to
+// the Dockerfile and falls back to the `.dockerignore` file at the root of
+// the build context.
+// The Dockerfile and the ignore file are always kept since the build needs
+// them.
+func FilterBuildContext(ctx context.Context, buildContext *dagger.Directory, dockerfile string) (*dagger.Directory, error) {
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	for _, ignoreFile := range []string{dockerfile + ".dockerignore", ".dockerignore"} {
+		matches, err := buildContext.Glob(ctx, ignoreFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look for %s: %w", ignoreFile, err)
+		}
+
+		if len(matches) == 0 {
+			continue
+		}
+
+		content, err := buildContext.File(ignoreFile).Contents(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", ignoreFile, err)
+		}
+
+		patterns, err := ignorefile.ReadAll(strings.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ignoreFile, err)
+		}
+
+		if len(patterns) == 0 {
+			return buildContext, nil
+		}
+
+		patterns = append(patterns, "!"+dockerfile, "!"+ignoreFile)
+
+		return dag.Directory().WithDirectory(".", buildContext, dagger.DirectoryWithDirectoryOpts{
+			Exclude: patterns,
+		}), nil
+	}
+
+	return buildContext, nil
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 // FormatEnvVariableName formats the given environment variable name
 //
@@ -39,4 +42,24 @@ func RemoveListDuplicates[T comparable](list []T) []T {
 		}
 	}
 	return list2
-}
\ No newline at end of file
+}
+
+// FormatName formats the given name into a PascalCase identifier.
+//
+// It splits the name on any non alphanumeric character and capitalizes
+// each part.
+//
+// For example, the input "services/api-gateway" will be converted to
+// "ServicesApiGateway".
+func FormatName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	res := []string{}
+	for _, part := range parts {
+		res = append(res, strings.ToUpper(part[0:1])+part[1:])
+	}
+
+	return strings.Join(res, "")
+}